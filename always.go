@@ -0,0 +1,56 @@
+package dagRun
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// AlwaysRun mark task always run after its dependencies finished, whatever they succeeded, failed,
+// skipped by branch or canceled, eg: cleanup of temp dir and notification. when run fails,
+// the always run tasks not started yet run with ctx not canceled, and their errors are joined to error of run
+func AlwaysRun() TaskOption {
+	return func(o *option) {
+		o.always = true
+	}
+}
+
+// runAlways run the pending always run tasks after run failed, level by level in topological order
+func (d *Scheduler[T]) runAlways(ctx context.Context, x T) {
+	ctx = context.WithoutCancel(ctx)
+	var pending = map[string]*node[T]{}
+	for _, n := range d.nodeList() {
+		if n.opt.always && n.getReport().Status == TaskPending {
+			pending[n.Name()] = n
+		}
+	}
+	for len(pending) > 0 {
+		var level []*node[T]
+		for _, n := range pending {
+			ready := true
+			for _, dep := range n.task.Dependencies() {
+				if _, ok := pending[dep]; ok {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, n)
+			}
+		}
+		if len(level) == 0 {
+			// circle among them
+			return
+		}
+		sort.Slice(level, func(i, j int) bool {
+			return level[i].Name() < level[j].Name()
+		})
+		d.swg = new(sync.WaitGroup)
+		d.swg.Add(len(level))
+		for _, n := range level {
+			delete(pending, n.Name())
+			n.start(ctx, x)
+		}
+		d.swg.Wait()
+	}
+}
@@ -0,0 +1,72 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAlwaysRun(t *testing.T) {
+	var lock sync.Mutex
+	var ran []string
+	var record = func(name string) func(context.Context, any) error {
+		return func(ctx context.Context, _ any) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			ran = append(ran, name)
+			return nil
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitFunc("A", func(ctx context.Context, _ any) error {
+		cancel()
+		return errors.New("expect err in A")
+	}))
+	checkNil(t, ds.SubmitFunc("B", record("B"), "A"))
+	checkNil(t, ds.SubmitFuncWithOps("C", record("C"), []TaskOption{AlwaysRun()}, "A"))
+	checkNil(t, ds.SubmitFuncWithOps("D", func(ctx context.Context, _ any) error {
+		_ = record("D")(ctx, nil)
+		return errors.New("expect err in D")
+	}, []TaskOption{AlwaysRun()}, "C"))
+	checkNil(t, ds.SubmitFuncWithOps("E", record("E"), []TaskOption{AlwaysRun()}, "B"))
+	err := ds.Run(ctx, nil)
+	checkNotNil(t, err)
+	checkEqual(t, true, strings.Contains(err.Error(), "expect err in A"))
+	checkEqual(t, true, strings.Contains(err.Error(), "expect err in D"))
+	checkEqual(t, 3, len(ran))
+	checkEqual(t, "D", ran[2])
+	report := ds.Report()
+	for name, status := range map[string]TaskStatus{"A": TaskFailed, "B": TaskCanceled,
+		"C": TaskSuccess, "D": TaskFailed, "E": TaskSuccess} {
+		tr, _ := report.Task(name)
+		checkEqual(t, status, tr.Status)
+	}
+}
+
+func TestAlwaysRunAfterBranch(t *testing.T) {
+	var ran []string
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitBranchFunc("B", func(ctx context.Context, _ any) (bool, error) { return false, nil }))
+	checkNil(t, ds.SubmitFunc("X", func(ctx context.Context, _ any) error {
+		ran = append(ran, "X")
+		return nil
+	}, "B"))
+	checkNil(t, ds.SubmitFuncWithOps("Y", func(ctx context.Context, _ any) error {
+		ran = append(ran, "Y")
+		return nil
+	}, []TaskOption{AlwaysRun()}, "B"))
+	checkNil(t, ds.Run(context.Background(), nil))
+	checkEqual(t, 1, len(ran))
+	checkEqual(t, "Y", ran[0])
+	plan, err := ds.Plan()
+	checkNil(t, err)
+	y, _ := plan.Task("Y")
+	checkEqual(t, true, y.AlwaysRun)
+	checkEqual(t, false, y.Conditional)
+}
@@ -0,0 +1,184 @@
+package dagRun
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cacheable is implemented by tasks which are pure functions of their inputs,
+// Execute is skipped and output is restored from cache when the same key is found
+type Cacheable[T any] interface {
+	Outputter[T]
+	// CacheKey return key of inputs of task, empty key means not cached in this run
+	CacheKey(ctx context.Context, t T) (string, error)
+}
+
+// Cache stores outputs of Cacheable tasks, it should be safe for concurrent use
+type Cache interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// WithCache set cache of outputs of Cacheable tasks
+func (d *Scheduler[T]) WithCache(cache Cache) *Scheduler[T] {
+	d.cache = cache
+	return d
+}
+
+// cacheKey return key of task in cache, empty if task not cached
+func (n *node[T]) cacheKey(ctx context.Context, t T) (string, error) {
+	ct, ok := n.task.(Cacheable[T])
+	if !ok || n.ds.cache == nil {
+		return "", nil
+	}
+	key, err := ct.CacheKey(ctx, t)
+	if err != nil {
+		return "", fmt.Errorf("dag: cache key of task:%s err:%w", n.Name(), err)
+	}
+	if key == "" {
+		return "", nil
+	}
+	// tasks may have the same key of inputs
+	return n.Name() + ":" + key, nil
+}
+
+// loadCache restore output of task from cache, return true on hit
+func (n *node[T]) loadCache(ctx context.Context, t T, key string) (bool, error) {
+	data, ok, err := n.ds.cache.Get(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("dag: get cache of task:%s err:%w", n.Name(), err)
+	}
+	if !ok {
+		return false, nil
+	}
+	if err := n.task.(Cacheable[T]).UnmarshalOutput(ctx, t, data); err != nil {
+		return false, fmt.Errorf("dag: restore cached output of task:%s err:%w", n.Name(), err)
+	}
+	return true, nil
+}
+
+func (n *node[T]) saveCache(ctx context.Context, t T, key string) error {
+	data, err := n.task.(Cacheable[T]).MarshalOutput(ctx, t)
+	if err != nil {
+		return fmt.Errorf("dag: marshal output of task:%s err:%w", n.Name(), err)
+	}
+	if err := n.ds.cache.Set(ctx, key, data); err != nil {
+		return fmt.Errorf("dag: set cache of task:%s err:%w", n.Name(), err)
+	}
+	return nil
+}
+
+// LRUCache is a Cache in memory which evicts the least recently used entry when full
+type LRUCache struct {
+	lock     sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+// NewLRUCache build a LRUCache keeping at most capacity entries
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRUCache{capacity: capacity, ll: list.New(), items: map[string]*list.Element{}}
+}
+
+// Get implements Cache
+func (c *LRUCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true, nil
+}
+
+// Set implements Cache
+func (c *LRUCache) Set(_ context.Context, key string, value []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.items[key]; ok {
+		e.Value.(*lruEntry).value = value
+		c.ll.MoveToFront(e)
+		return nil
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value})
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Len return number of entries in cache
+func (c *LRUCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.ll.Len()
+}
+
+// FileCache is a Cache saving each entry in a file of dir, named by sha256 of key
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache build a FileCache, dir is created if not exist
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func (f *FileCache) filename(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get implements Cache
+func (f *FileCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(f.filename(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Set implements Cache, entry is written to a temp file and renamed, so a reader never sees a partial one
+func (f *FileCache) Set(_ context.Context, key string, value []byte) error {
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(value); err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.filename(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package dagRun
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+type cachedTask struct {
+	outputTask
+}
+
+func (c cachedTask) CacheKey(_ context.Context, x *counterCtx) (string, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	key := ""
+	for _, dep := range c.deps {
+		key += fmt.Sprintf("%s=%d;", dep, x.outputs[dep])
+	}
+	return key, nil
+}
+
+func testCache(t *testing.T, cache Cache) {
+	var run = func() (*counterCtx, *RunReport) {
+		ds := NewScheduler[*counterCtx]().WithCache(cache)
+		checkNil(t, ds.Submit(
+			outputTask{name: "T1"},
+			cachedTask{outputTask{name: "T2", deps: []string{"T1"}}},
+			cachedTask{outputTask{name: "T3", deps: []string{"T2"}}},
+		))
+		x := newCounterCtx()
+		checkNil(t, ds.Run(context.Background(), x))
+		return x, ds.Report()
+	}
+	x, report := run()
+	checkEqual(t, 1, x.runs["T2"])
+	checkEqual(t, 1, x.runs["T3"])
+	tr, _ := report.Task("T2")
+	checkEqual(t, TaskSuccess, tr.Status)
+
+	x, report = run()
+	checkEqual(t, 1, x.runs["T1"])
+	checkEqual(t, 0, x.runs["T2"])
+	checkEqual(t, 0, x.runs["T3"])
+	checkEqual(t, 3, x.outputs["T3"])
+	for _, name := range []string{"T2", "T3"} {
+		tr, _ := report.Task(name)
+		checkEqual(t, TaskCached, tr.Status)
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	testCache(t, NewLRUCache(10))
+
+	ctx := context.Background()
+	cache := NewLRUCache(2)
+	checkNil(t, cache.Set(ctx, "a", []byte("1")))
+	checkNil(t, cache.Set(ctx, "b", []byte("2")))
+	_, ok, _ := cache.Get(ctx, "a")
+	checkEqual(t, true, ok)
+	checkNil(t, cache.Set(ctx, "c", []byte("3")))
+	checkEqual(t, 2, cache.Len())
+	_, ok, _ = cache.Get(ctx, "b")
+	checkEqual(t, false, ok)
+	v, ok, _ := cache.Get(ctx, "a")
+	checkEqual(t, true, ok)
+	checkEqual(t, "1", string(v))
+}
+
+func TestFileCache(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir())
+	checkNil(t, err)
+	testCache(t, cache)
+
+	ctx := context.Background()
+	_, ok, err := cache.Get(ctx, "not exist")
+	checkNil(t, err)
+	checkEqual(t, false, ok)
+	checkNil(t, cache.Set(ctx, "../key", []byte("v")))
+	v, ok, err := cache.Get(ctx, "../key")
+	checkNil(t, err)
+	checkEqual(t, true, ok)
+	checkEqual(t, "v", string(v))
+}
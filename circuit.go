@@ -0,0 +1,145 @@
+package dagRun
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // task runs normally
+	CircuitOpen     CircuitState = "open"      // task fails immediately with ErrCircuitOpen
+	CircuitHalfOpen CircuitState = "half-open" // one trial run decides to close or open again
+)
+
+// breakerOption is the config of circuit breaker set by option
+type breakerOption struct {
+	threshold int
+	coolDown  time.Duration
+}
+
+// CircuitBreaker set a circuit breaker of task, which is shared by all runs of tasks with the same name
+// in the process. after threshold consecutive failures the circuit opens, and task fails immediately
+// with ErrCircuitOpen (or runs its fallback) in coolDown, measured on clock of scheduler.
+// then the circuit half-opens, and one trial run closes it if succeeded or opens it again if failed.
+// tasks with the same name must have the same circuit breaker
+func CircuitBreaker(threshold int, coolDown time.Duration) TaskOption {
+	return func(o *option) {
+		o.breaker = &breakerOption{threshold: threshold, coolDown: coolDown}
+	}
+}
+
+// Circuit return state of circuit breaker of task, CircuitClosed if task has no circuit breaker
+func Circuit(name string) CircuitState {
+	if v, ok := circuits.Load(name); ok {
+		c := v.(*circuit)
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		return c.state
+	}
+	return CircuitClosed
+}
+
+// ResetCircuit close circuit breaker of task, eg: after the downstream recovered.
+// it's built again by the next task with the name, which may have another circuit breaker
+func ResetCircuit(name string) {
+	circuits.Delete(name)
+}
+
+// circuits are circuit breakers by task name
+var circuits sync.Map
+
+type circuit struct {
+	o        breakerOption
+	lock     sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trying   bool // a trial run is running when half-open
+}
+
+// circuitOf return circuit breaker of task, error if it's built by another circuit breaker
+func circuitOf(name string, o *breakerOption) (*circuit, error) {
+	v, _ := circuits.LoadOrStore(name, &circuit{o: *o, state: CircuitClosed})
+	c := v.(*circuit)
+	if c.o != *o {
+		return nil, fmt.Errorf("%w: task:%s circuit breaker threshold:%d cool down:%s conflicts with threshold:%d cool down:%s of the same name",
+			ErrInvalidOption, name, o.threshold, o.coolDown, c.o.threshold, c.o.coolDown)
+	}
+	return c, nil
+}
+
+// allow tell whether task can run now
+func (c *circuit) allow(now time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	switch c.state {
+	case CircuitOpen:
+		if now.Sub(c.openedAt) < c.o.coolDown {
+			return false
+		}
+		c.state = CircuitHalfOpen
+		c.trying = true
+		return true
+	case CircuitHalfOpen:
+		if c.trying {
+			return false
+		}
+		c.trying = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record result of a run allowed, run not finished by itself like canceled is ignored
+func (c *circuit) record(now time.Time, ok, ignored bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	trial := c.state == CircuitHalfOpen
+	if trial {
+		c.trying = false
+	}
+	switch {
+	case ignored:
+	case ok:
+		c.state, c.failures = CircuitClosed, 0
+	case trial:
+		c.state, c.openedAt = CircuitOpen, now
+	default:
+		c.failures++
+		if c.failures >= c.o.threshold {
+			c.state, c.openedAt = CircuitOpen, now
+		}
+	}
+}
+
+// execute run handler of node through its circuit breaker if set
+func (n *node[T]) execute(ctx context.Context, t T) error {
+	o := n.opt.breaker
+	if o == nil {
+		return n.handle(ctx, n.task, t)
+	}
+	c, err := circuitOf(n.Name(), o)
+	if err != nil {
+		return err
+	}
+	if !c.allow(n.ds.clock.Now()) {
+		return fmt.Errorf("%w: task:%s", ErrCircuitOpen, n.Name())
+	}
+	var recorded bool
+	defer func() {
+		// task panicked
+		if !recorded {
+			c.record(n.ds.clock.Now(), false, false)
+		}
+	}()
+	err = n.handle(ctx, n.task, t)
+	c.record(n.ds.clock.Now(), err == nil, err != nil && ctx.Err() != nil)
+	recorded = true
+	return err
+}
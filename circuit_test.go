@@ -0,0 +1,127 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	const name = "TestCircuitBreaker"
+	defer ResetCircuit(name)
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	var calls int
+	var fail = true
+	var run = func(at time.Duration, ops ...TaskOption) error {
+		ds := NewScheduler[any]().WithClock(fixedClock{now: now.Add(at)})
+		checkNil(t, ds.SubmitFuncWithOps(name, func(ctx context.Context, _ any) error {
+			calls++
+			if fail {
+				return errors.New("expect err")
+			}
+			return nil
+		}, append([]TaskOption{CircuitBreaker(2, time.Minute)}, ops...)))
+		return ds.Run(context.Background(), nil)
+	}
+	checkNotNil(t, run(0))
+	checkEqual(t, CircuitClosed, Circuit(name))
+	checkNotNil(t, run(time.Second))
+	checkEqual(t, CircuitOpen, Circuit(name))
+	checkEqual(t, 2, calls)
+
+	// fail immediately in cool down
+	err := run(30 * time.Second)
+	checkEqual(t, true, errors.Is(err, ErrCircuitOpen))
+	checkEqual(t, 2, calls)
+	checkNil(t, run(30*time.Second, FallbackFunc(func(ctx context.Context, err error) error {
+		checkEqual(t, true, errors.Is(err, ErrCircuitOpen))
+		return nil
+	})))
+	checkEqual(t, 2, calls)
+
+	// trial failed and opens again
+	checkNotNil(t, run(2*time.Minute))
+	checkEqual(t, 3, calls)
+	checkEqual(t, CircuitOpen, Circuit(name))
+	checkEqual(t, true, errors.Is(run(2*time.Minute+time.Second), ErrCircuitOpen))
+
+	// trial succeeded and closes
+	fail = false
+	checkNil(t, run(4*time.Minute))
+	checkEqual(t, 4, calls)
+	checkEqual(t, CircuitClosed, Circuit(name))
+	checkNil(t, run(4*time.Minute))
+	checkEqual(t, 5, calls)
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	const name = "TestCircuitBreakerHalfOpen"
+	defer ResetCircuit(name)
+	c, err := circuitOf(name, &breakerOption{threshold: 1, coolDown: time.Minute})
+	checkNil(t, err)
+	now := time.Now()
+	checkEqual(t, true, c.allow(now))
+	c.record(now, false, false)
+	checkEqual(t, CircuitOpen, Circuit(name))
+
+	// only one of concurrent runs is the trial
+	var calls, finished atomic.Int32
+	var release = make(chan struct{})
+	var wg sync.WaitGroup
+	var errs = make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ds := NewScheduler[any]().WithClock(fixedClock{now: now.Add(time.Hour)})
+			checkNil(t, ds.SubmitFuncWithOps(name, func(ctx context.Context, _ any) error {
+				calls.Add(1)
+				<-release
+				return nil
+			}, []TaskOption{CircuitBreaker(1, time.Minute)}))
+			errs[i] = ds.Run(context.Background(), nil)
+			finished.Add(1)
+		}(i)
+	}
+	// others are rejected while the trial is running
+	for finished.Load() < int32(len(errs)-1) {
+		time.Sleep(time.Millisecond)
+	}
+	checkEqual(t, CircuitHalfOpen, Circuit(name))
+	close(release)
+	wg.Wait()
+	checkEqual(t, int32(1), calls.Load())
+	var rejected int
+	for _, err := range errs {
+		if errors.Is(err, ErrCircuitOpen) {
+			rejected++
+		}
+	}
+	checkEqual(t, 9, rejected)
+	checkEqual(t, CircuitClosed, Circuit(name))
+}
+
+func TestCircuitBreakerOption(t *testing.T) {
+	err := NewScheduler[any]().SubmitFuncWithOps("T", func(ctx context.Context, _ any) error { return nil },
+		[]TaskOption{CircuitBreaker(0, time.Minute)})
+	checkEqual(t, true, errors.Is(err, ErrInvalidOption))
+	checkEqual(t, CircuitClosed, Circuit("T"))
+
+	// tasks with the same name share the circuit breaker
+	const name = "TestCircuitBreakerOption"
+	defer ResetCircuit(name)
+	var submit = func(ops ...TaskOption) error {
+		return NewScheduler[any]().SubmitFuncWithOps(name, func(ctx context.Context, _ any) error { return nil }, ops)
+	}
+	checkNil(t, submit(CircuitBreaker(2, time.Minute)))
+	checkNil(t, submit(CircuitBreaker(2, time.Minute)))
+	err = submit(CircuitBreaker(3, time.Minute))
+	checkEqual(t, true, errors.Is(err, ErrInvalidOption))
+	err = submit(CircuitBreaker(2, time.Second))
+	checkEqual(t, true, errors.Is(err, ErrInvalidOption))
+	ResetCircuit(name)
+	checkNil(t, submit(CircuitBreaker(3, time.Minute)))
+}
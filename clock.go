@@ -0,0 +1,90 @@
+package dagRun
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time of scheduler, timestamps of reports, task timeouts and WaitTimeout
+// are measured on it. replace it with a fake one like dagtest.Clock to test time deterministically
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// Timer is the timer of Clock
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// SystemClock is the Clock of real time
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) NewTimer(d time.Duration) Timer         { return systemTimer{time.NewTimer(d)} }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time { return t.t.C }
+func (t systemTimer) Stop() bool          { return t.t.Stop() }
+
+// WithClock set clock of scheduler, SystemClock by default
+func (d *Scheduler[T]) WithClock(clock Clock) *Scheduler[T] {
+	d.clock = clock
+	return d
+}
+
+// withTimeout is context.WithTimeout measured on clock
+func withTimeout(ctx context.Context, clock Clock, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := clock.(systemClock); ok {
+		return context.WithTimeout(ctx, timeout)
+	}
+	deadline := clock.Now().Add(timeout)
+	cctx, cancel := context.WithCancelCause(ctx)
+	timer := clock.NewTimer(timeout)
+	go func() {
+		select {
+		case <-timer.C():
+			cancel(context.DeadlineExceeded)
+		case <-cctx.Done():
+			timer.Stop()
+		}
+	}()
+	return &deadlineCtx{Context: cctx, deadline: deadline}, func() {
+		// timer may not be received yet when deadline passed
+		if !clock.Now().Before(deadline) {
+			cancel(context.DeadlineExceeded)
+		}
+		cancel(context.Canceled)
+	}
+}
+
+// deadlineCtx is a ctx canceled by a timer of Clock
+type deadlineCtx struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c *deadlineCtx) Deadline() (time.Time, bool) {
+	if parent, ok := c.Context.Deadline(); ok && parent.Before(c.deadline) {
+		return parent, true
+	}
+	return c.deadline, true
+}
+
+func (c *deadlineCtx) Err() error {
+	err := c.Context.Err()
+	if err != nil && context.Cause(c.Context) == context.DeadlineExceeded {
+		return context.DeadlineExceeded
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package dagRun
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fixedClock is SystemClock except Now
+type fixedClock struct {
+	systemClock
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestWithClock(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	ds := NewFuncScheduler().WithClock(fixedClock{now: now})
+	ds.Submit("T1", func() error { return nil })
+	checkNil(t, ds.Run())
+	report := ds.Report()
+	checkEqual(t, true, report.Start.Equal(now))
+	checkEqual(t, true, report.Tasks[0].End.Equal(now))
+}
+
+func TestWithTimeoutOnClock(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx, cancel := withTimeout(context.Background(), fixedClock{now: now}, 10*time.Millisecond)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	checkEqual(t, true, ok)
+	checkEqual(t, true, deadline.Equal(now.Add(10*time.Millisecond)))
+	<-ctx.Done()
+	checkEqual(t, context.DeadlineExceeded, ctx.Err())
+
+	ctx, cancel = withTimeout(context.Background(), fixedClock{now: now}, time.Hour)
+	cancel()
+	checkEqual(t, context.Canceled, ctx.Err())
+}
@@ -0,0 +1,88 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Compensator is implemented by tasks which can undo their side effects, eg: release reservations.
+// when run fails, Compensate of completed tasks are called in reverse order of their completion
+type Compensator[T any] interface {
+	Compensate(ctx context.Context, t T) error
+}
+
+// CompensateWith set the compensation of task, it overrides Compensator of task.
+// the type T must be the same with the scheduler which task is submitted to
+func CompensateWith[T any](f func(ctx context.Context, t T) error) TaskOption {
+	return func(o *option) {
+		o.compensate = f
+	}
+}
+
+// CompensateFunc set the compensation of task without runCtx, eg: for tasks of FuncScheduler
+func CompensateFunc(f func(ctx context.Context) error) TaskOption {
+	return func(o *option) {
+		o.compensate = f
+	}
+}
+
+// checkCompensate check type of compensation set by option
+func checkCompensate[T any](name string, o option) error {
+	switch o.compensate.(type) {
+	case nil, func(context.Context, T) error, func(context.Context) error:
+		return nil
+	default:
+		return fmt.Errorf("%w: task:%s compensation type:%T", ErrInvalidOption, name, o.compensate)
+	}
+}
+
+// compensation return compensation of node, nil if not set
+func (n *node[T]) compensation() func(context.Context, T) error {
+	switch f := n.opt.compensate.(type) {
+	case func(context.Context, T) error:
+		return f
+	case func(context.Context) error:
+		return func(ctx context.Context, _ T) error { return f(ctx) }
+	}
+	if c, ok := n.task.(Compensator[T]); ok {
+		return c.Compensate
+	}
+	return nil
+}
+
+// compensate call compensations of completed tasks in reverse order of their completion,
+// errors of compensations are joined to err
+func (d *Scheduler[T]) compensate(ctx context.Context, x T, err error) error {
+	// compensations should finish even if run is canceled
+	ctx = context.WithoutCancel(ctx)
+	var nodes []*node[T]
+	for _, n := range d.nodeList() {
+		status := n.getReport().Status
+		if (status == TaskSuccess || status == TaskRestored) && n.compensation() != nil {
+			nodes = append(nodes, n)
+		}
+	}
+	d.lock.Lock()
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].order > nodes[j].order
+	})
+	d.lock.Unlock()
+	for _, n := range nodes {
+		if cErr := n.compensation()(ctx, x); cErr != nil {
+			err = errors.Join(err, fmt.Errorf("dag: compensate task:%s err:%w", n.Name(), cErr))
+			continue
+		}
+		n.mu.Lock()
+		n.report.Status = TaskCompensated
+		n.mu.Unlock()
+		if d.store != nil {
+			state := TaskState{Task: n.Name(), End: d.clock.Now(), Compensated: true}
+			if sErr := d.store.Save(ctx, d.runID, state); sErr != nil {
+				err = errors.Join(err, fmt.Errorf("dag: save state of compensated task:%s err:%w", n.Name(), sErr))
+			}
+		}
+	}
+	return err
+}
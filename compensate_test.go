@@ -0,0 +1,118 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type compensateTask struct {
+	name        string
+	deps        []string
+	options     []TaskOption
+	err         error
+	lock        *sync.Mutex
+	compensated *[]string
+}
+
+func (c compensateTask) Name() string           { return c.name }
+func (c compensateTask) Dependencies() []string { return c.deps }
+func (c compensateTask) Options() []TaskOption  { return c.options }
+
+func (c compensateTask) Execute(context.Context, any) error {
+	return c.err
+}
+
+func (c compensateTask) Compensate(context.Context, any) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	*c.compensated = append(*c.compensated, c.name)
+	return nil
+}
+
+func TestCompensate(t *testing.T) {
+	var lock sync.Mutex
+	var compensated []string
+	var record = func(name string) {
+		lock.Lock()
+		defer lock.Unlock()
+		compensated = append(compensated, name)
+	}
+	ds := NewScheduler[any]()
+	checkNil(t, ds.Submit(
+		compensateTask{name: "A", lock: &lock, compensated: &compensated},
+		compensateTask{name: "B", deps: []string{"A"}, lock: &lock, compensated: &compensated,
+			options: []TaskOption{CompensateWith(func(ctx context.Context, _ any) error {
+				record("B by option")
+				return nil
+			})}},
+		compensateTask{name: "C", deps: []string{"B"}, err: errors.New("expect err in C"),
+			lock: &lock, compensated: &compensated},
+		compensateTask{name: "D", deps: []string{"A"}, lock: &lock, compensated: &compensated,
+			options: []TaskOption{CompensateFunc(func(ctx context.Context) error {
+				record("D")
+				return errors.New("expect compensate err in D")
+			})}},
+	))
+	err := ds.Run(context.Background(), nil)
+	checkNotNil(t, err)
+	checkEqual(t, true, strings.Contains(err.Error(), "expect err in C"))
+	checkEqual(t, true, strings.Contains(err.Error(), "expect compensate err in D"))
+	checkEqual(t, 3, len(compensated))
+	checkEqual(t, "A", compensated[2])
+	report := ds.Report()
+	for name, status := range map[string]TaskStatus{"A": TaskCompensated, "B": TaskCompensated,
+		"C": TaskFailed, "D": TaskSuccess} {
+		tr, _ := report.Task(name)
+		checkEqual(t, status, tr.Status)
+	}
+
+	// nothing compensated when run succeeded
+	compensated = nil
+	ds = NewScheduler[any]()
+	checkNil(t, ds.Submit(compensateTask{name: "A", lock: &lock, compensated: &compensated}))
+	checkNil(t, ds.Run(context.Background(), nil))
+	checkEqual(t, 0, len(compensated))
+
+	err = NewScheduler[any]().Submit(compensateTask{name: "A",
+		options: []TaskOption{CompensateWith(func(ctx context.Context, _ int) error { return nil })}})
+	checkEqual(t, true, errors.Is(err, ErrInvalidOption))
+}
+
+func TestCompensateResume(t *testing.T) {
+	var reserved, runs int
+	var failB = true
+	var build = func(store StateStore) *Scheduler[any] {
+		ds := NewScheduler[any]().WithStateStore(store)
+		checkNil(t, ds.SubmitFuncWithOps("A", func(ctx context.Context, _ any) error {
+			runs++
+			reserved++
+			return nil
+		}, []TaskOption{CompensateFunc(func(ctx context.Context) error {
+			reserved--
+			return nil
+		})}))
+		checkNil(t, ds.SubmitFunc("B", func(ctx context.Context, _ any) error {
+			if failB {
+				return errors.New("expect err in B")
+			}
+			return nil
+		}, "A"))
+		return ds
+	}
+	store := NewMemoryStateStore()
+	ds := build(store)
+	checkNotNil(t, ds.Run(context.Background(), nil))
+	checkEqual(t, 0, reserved)
+
+	// compensated task runs again when resumed
+	failB = false
+	ds2 := build(store)
+	checkNil(t, ds2.Resume(context.Background(), ds.RunID(), nil))
+	checkEqual(t, 2, runs)
+	checkEqual(t, 1, reserved)
+	tr, _ := ds2.Report().Task("A")
+	checkEqual(t, TaskSuccess, tr.Status)
+}
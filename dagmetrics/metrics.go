@@ -0,0 +1,115 @@
+// Package dagmetrics collects metrics of dagRun schedulers, such as runs, task durations,
+// retries, timeouts, hedges, skipped tasks, queue wait time, rate limit wait time and in-flight tasks.
+// see Prometheus for an adapter exposing them in prometheus text format.
+package dagmetrics
+
+import (
+	"context"
+	"errors"
+
+	dagRun "github.com/ycl2018/dag-run"
+)
+
+// names of metrics
+const (
+	RunsStarted    = "dag_runs_started_total"
+	RunsFinished   = "dag_runs_finished_total"
+	RunDuration    = "dag_run_duration_seconds"
+	TaskDuration   = "dag_task_duration_seconds"
+	TaskRetries    = "dag_task_retries_total"
+	TaskTimeouts   = "dag_task_timeouts_total"
+	TaskSkipped    = "dag_task_skipped_total"
+	TaskQueueWait  = "dag_task_queue_wait_seconds"
+	TaskThrottled  = "dag_task_throttled_seconds"
+	TaskHedges     = "dag_task_hedges_total"
+	TaskHedgeWins  = "dag_task_hedge_wins_total"
+	TasksInFlight  = "dag_tasks_in_flight"
+	LabelScheduler = "scheduler"
+	LabelTask      = "task"
+	LabelOutcome   = "outcome"
+	LabelReason    = "reason"
+)
+
+// Labels of a metric
+type Labels map[string]string
+
+// Metrics records metrics, it should be safe for concurrent use
+type Metrics interface {
+	// Counter add delta to a counter
+	Counter(name string, labels Labels, delta float64)
+	// Gauge add delta to a gauge
+	Gauge(name string, labels Labels, delta float64)
+	// Histogram observe a value
+	Histogram(name string, labels Labels, value float64)
+}
+
+// NewListener build a listener which records metrics of scheduler to m,
+// one listener can be shared by all schedulers
+func NewListener(m Metrics) dagRun.Listener {
+	return listener{m: m}
+}
+
+type listener struct {
+	dagRun.NopListener
+	m Metrics
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+func (l listener) OnRunStart(_ context.Context, e dagRun.RunEvent) {
+	l.m.Counter(RunsStarted, Labels{LabelScheduler: e.Scheduler}, 1)
+}
+
+func (l listener) OnRunEnd(_ context.Context, e dagRun.RunEvent) {
+	labels := Labels{LabelScheduler: e.Scheduler, LabelOutcome: outcome(e.Err)}
+	l.m.Counter(RunsFinished, labels, 1)
+	l.m.Histogram(RunDuration, labels, e.Duration().Seconds())
+}
+
+func (l listener) OnTaskStart(_ context.Context, e dagRun.TaskEvent) {
+	labels := Labels{LabelScheduler: e.Scheduler, LabelTask: e.Task}
+	l.m.Gauge(TasksInFlight, Labels{LabelScheduler: e.Scheduler}, 1)
+	if !e.Ready.IsZero() {
+		l.m.Histogram(TaskQueueWait, labels, e.Start.Sub(e.Ready).Seconds())
+	}
+}
+
+func (l listener) OnTaskRetry(_ context.Context, e dagRun.TaskEvent) {
+	l.m.Counter(TaskRetries, Labels{LabelScheduler: e.Scheduler, LabelTask: e.Task}, 1)
+}
+
+func (l listener) OnTaskSkip(_ context.Context, e dagRun.TaskEvent) {
+	reason := "branch"
+	if errors.Is(e.Err, dagRun.ErrCanceled) {
+		reason = "canceled"
+	}
+	l.m.Counter(TaskSkipped, Labels{LabelScheduler: e.Scheduler, LabelTask: e.Task, LabelReason: reason}, 1)
+}
+
+func (l listener) OnTaskEnd(_ context.Context, e dagRun.TaskEvent) {
+	if e.Attempt > 0 {
+		l.m.Gauge(TasksInFlight, Labels{LabelScheduler: e.Scheduler}, -1)
+	}
+	if errors.Is(e.Err, dagRun.ErrTaskTimeout) {
+		l.m.Counter(TaskTimeouts, Labels{LabelScheduler: e.Scheduler, LabelTask: e.Task}, 1)
+	}
+	if e.Hedged > 0 {
+		l.m.Counter(TaskHedges, Labels{LabelScheduler: e.Scheduler, LabelTask: e.Task}, float64(e.Hedged))
+		l.m.Counter(TaskHedgeWins, Labels{LabelScheduler: e.Scheduler, LabelTask: e.Task}, float64(e.HedgeWins))
+	}
+	if !e.Start.IsZero() {
+		labels := Labels{LabelScheduler: e.Scheduler, LabelTask: e.Task, LabelOutcome: outcome(e.Err)}
+		l.m.Histogram(TaskDuration, labels, e.Duration().Seconds())
+	}
+	if e.Throttled > 0 {
+		l.m.Histogram(TaskThrottled, Labels{LabelScheduler: e.Scheduler, LabelTask: e.Task}, e.Throttled.Seconds())
+	}
+}
+
+// DefBuckets is the default buckets of histograms in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300, 600}
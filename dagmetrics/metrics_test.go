@@ -0,0 +1,82 @@
+package dagmetrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dagRun "github.com/ycl2018/dag-run"
+)
+
+func TestListener(t *testing.T) {
+	prom := NewPrometheus(0.01, 1)
+	s := dagRun.NewScheduler[any]().WithName("metrics").WithListener(NewListener(prom))
+	var tries int
+	_ = s.SubmitFuncWithOps("T1", func(ctx context.Context, _ any) error {
+		tries++
+		if tries < 2 {
+			return errors.New("expect err in T1")
+		}
+		return nil
+	}, []dagRun.TaskOption{dagRun.Retry(2), dagRun.RateLimit(1, 10*time.Millisecond)})
+	_ = s.SubmitBranchFunc("B1", func(ctx context.Context, _ any) (bool, error) { return false, nil })
+	_ = s.SubmitFunc("T2", func(ctx context.Context, _ any) error { return nil }, "B1")
+	_ = s.SubmitFuncWithOps("T3", func(ctx context.Context, _ any) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}, []dagRun.TaskOption{dagRun.Timeout(20 * time.Millisecond)}, "T1")
+	_ = s.SubmitFunc("T4", func(ctx context.Context, _ any) error { return nil }, "T3")
+	_ = s.SubmitFuncWithOps("T5", func(ctx context.Context, _ any) error {
+		if info, _ := dagRun.InfoFromContext(ctx); !info.Hedge {
+			<-ctx.Done()
+		}
+		return nil
+	}, []dagRun.TaskOption{dagRun.Hedge(time.Millisecond)})
+	if err := s.Run(context.Background(), nil); !errors.Is(err, dagRun.ErrTaskTimeout) {
+		t.Fatalf("want timeout err but get:%v", err)
+	}
+	var buf bytes.Buffer
+	if err := prom.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	text := buf.String()
+	wants := []string{
+		"# TYPE dag_runs_started_total counter\ndag_runs_started_total{scheduler=\"metrics\"} 1\n",
+		"dag_runs_finished_total{outcome=\"failure\",scheduler=\"metrics\"} 1\n",
+		"dag_run_duration_seconds_bucket{outcome=\"failure\",scheduler=\"metrics\",le=\"0.01\"} 0\n",
+		"dag_run_duration_seconds_bucket{outcome=\"failure\",scheduler=\"metrics\",le=\"1\"} 1\n",
+		"dag_run_duration_seconds_count{outcome=\"failure\",scheduler=\"metrics\"} 1\n",
+		"dag_task_retries_total{scheduler=\"metrics\",task=\"T1\"} 1\n",
+		"dag_task_timeouts_total{scheduler=\"metrics\",task=\"T3\"} 1\n",
+		"dag_task_hedges_total{scheduler=\"metrics\",task=\"T5\"} 1\n",
+		"dag_task_hedge_wins_total{scheduler=\"metrics\",task=\"T5\"} 1\n",
+		"dag_task_skipped_total{reason=\"branch\",scheduler=\"metrics\",task=\"T2\"} 1\n",
+		"dag_task_skipped_total{reason=\"canceled\",scheduler=\"metrics\",task=\"T4\"} 1\n",
+		"dag_task_duration_seconds_count{outcome=\"failure\",scheduler=\"metrics\",task=\"T3\"} 1\n",
+		"dag_task_duration_seconds_count{outcome=\"success\",scheduler=\"metrics\",task=\"T1\"} 1\n",
+		"dag_task_throttled_seconds_count{scheduler=\"metrics\",task=\"T1\"} 1\n",
+		"dag_task_queue_wait_seconds_count{scheduler=\"metrics\",task=\"B1\"} 1\n",
+		"# TYPE dag_tasks_in_flight gauge\ndag_tasks_in_flight{scheduler=\"metrics\"} 0\n",
+	}
+	for _, want := range wants {
+		if !strings.Contains(text, want) {
+			t.Errorf("want %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	prom := NewPrometheus()
+	prom.Counter("c", nil, 2)
+	prom.Gauge("g", Labels{"a": `"x"`}, 1.5)
+	recorder := httptest.NewRecorder()
+	prom.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	want := "# TYPE c counter\nc 2\n# TYPE g gauge\ng{a=\"\\\"x\\\"\"} 1.5\n"
+	if recorder.Body.String() != want {
+		t.Errorf("want:%q but get:%q", want, recorder.Body.String())
+	}
+}
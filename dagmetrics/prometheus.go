@@ -0,0 +1,165 @@
+package dagmetrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Prometheus implements Metrics in memory and exposes them in prometheus text format
+type Prometheus struct {
+	lock     sync.Mutex
+	buckets  []float64
+	families map[string]*family
+}
+
+type family struct {
+	kind   string
+	series map[string]*series
+}
+
+type series struct {
+	labels  string
+	value   float64
+	counts  []uint64
+	sum     float64
+	samples uint64
+}
+
+// NewPrometheus build a Prometheus, DefBuckets is used for histograms if buckets is empty
+func NewPrometheus(buckets ...float64) *Prometheus {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Prometheus{buckets: buckets, families: map[string]*family{}}
+}
+
+func (p *Prometheus) series(kind, name string, labels Labels) *series {
+	f, ok := p.families[name]
+	if !ok {
+		f = &family{kind: kind, series: map[string]*series{}}
+		p.families[name] = f
+	}
+	key := formatLabels(labels)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: key}
+		if kind == "histogram" {
+			s.counts = make([]uint64, len(p.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Counter implements Metrics
+func (p *Prometheus) Counter(name string, labels Labels, delta float64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.series("counter", name, labels).value += delta
+}
+
+// Gauge implements Metrics
+func (p *Prometheus) Gauge(name string, labels Labels, delta float64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.series("gauge", name, labels).value += delta
+}
+
+// Histogram implements Metrics
+func (p *Prometheus) Histogram(name string, labels Labels, value float64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	s := p.series("histogram", name, labels)
+	for i, le := range p.buckets {
+		if value <= le {
+			s.counts[i]++
+		}
+	}
+	s.sum += value
+	s.samples++
+}
+
+// WriteText write all metrics in prometheus text format, sorted by name and labels
+func (p *Prometheus) WriteText(w io.Writer) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	bw := bufio.NewWriter(w)
+	var names []string
+	for name := range p.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := p.families[name]
+		bw.WriteString("# TYPE " + name + " " + f.kind + "\n")
+		var keys []string
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s := f.series[key]
+			if f.kind != "histogram" {
+				bw.WriteString(name + braces(s.labels) + " " + formatFloat(s.value) + "\n")
+				continue
+			}
+			for i, le := range p.buckets {
+				bw.WriteString(name + "_bucket" + braces(joinLabels(s.labels, `le="`+formatFloat(le)+`"`)) +
+					" " + strconv.FormatUint(s.counts[i], 10) + "\n")
+			}
+			bw.WriteString(name + "_bucket" + braces(joinLabels(s.labels, `le="+Inf"`)) +
+				" " + strconv.FormatUint(s.samples, 10) + "\n")
+			bw.WriteString(name + "_sum" + braces(s.labels) + " " + formatFloat(s.sum) + "\n")
+			bw.WriteString(name + "_count" + braces(s.labels) + " " + strconv.FormatUint(s.samples, 10) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP implements http.Handler, it can be mounted as the /metrics endpoint
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = p.WriteText(w)
+}
+
+func formatLabels(labels Labels) string {
+	var keys []string
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+strconv.Quote(labels[k]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func joinLabels(labels, extra string) string {
+	if labels == "" {
+		return extra
+	}
+	return labels + "," + extra
+}
+
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
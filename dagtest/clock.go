@@ -0,0 +1,146 @@
+package dagtest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	dagRun "github.com/ycl2018/dag-run"
+)
+
+var _ dagRun.Clock = (*Clock)(nil)
+
+// Clock is a fake clock implementing dagRun.Clock, time only moves forward by Advance,
+// so timeouts of scheduler and tasks sleeping on it are driven by test without real sleeping
+type Clock struct {
+	lock    sync.Mutex
+	now     time.Time
+	timers  []*Timer
+	changed chan struct{}
+}
+
+// NewClock build a fake clock starting at start
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start, changed: make(chan struct{})}
+}
+
+// Now return current time of clock
+func (c *Clock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// NewTimer build a timer firing after d on clock
+func (c *Clock) NewTimer(d time.Duration) dagRun.Timer {
+	return c.newTimer(d)
+}
+
+func (c *Clock) newTimer(d time.Duration) *Timer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	t := &Timer{c: c, ch: make(chan time.Time, 1), when: c.now.Add(d)}
+	if d <= 0 {
+		t.ch <- c.now
+		t.fired = true
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.notify()
+	return t
+}
+
+// After return a channel receiving time after d on clock
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.newTimer(d).C()
+}
+
+// Sleep block until clock advanced d
+func (c *Clock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// Advance move clock forward d, timers due are fired in order of time
+func (c *Clock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	end := c.now.Add(d)
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].when.Before(c.timers[j].when)
+	})
+	var pending []*Timer
+	for _, t := range c.timers {
+		if t.when.After(end) {
+			pending = append(pending, t)
+			continue
+		}
+		c.now = t.when
+		t.fired = true
+		t.ch <- t.when
+	}
+	c.timers = pending
+	c.now = end
+	c.notify()
+}
+
+// Waiters return number of timers not fired
+func (c *Clock) Waiters() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil block until there are at least n timers not fired, eg: n tasks are sleeping on clock
+func (c *Clock) BlockUntil(n int) {
+	for {
+		c.lock.Lock()
+		if len(c.timers) >= n {
+			c.lock.Unlock()
+			return
+		}
+		changed := c.changed
+		c.lock.Unlock()
+		<-changed
+	}
+}
+
+// notify wakes up BlockUntil, must be called with lock held
+func (c *Clock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *Clock) stop(t *Timer) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if t.fired {
+		return false
+	}
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			break
+		}
+	}
+	t.fired = true
+	c.notify()
+	return true
+}
+
+// Timer is a timer of fake clock
+type Timer struct {
+	c     *Clock
+	ch    chan time.Time
+	when  time.Time
+	fired bool
+}
+
+// C return the channel receiving time when timer fires
+func (t *Timer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop prevent timer from firing, return false if timer already fired or stopped
+func (t *Timer) Stop() bool {
+	return t.c.stop(t)
+}
@@ -0,0 +1,371 @@
+// Package dagtest is a deterministic test harness for dagRun schedulers: fake tasks with scripted
+// outcomes, a fake clock driving simulated durations, and assertions on execution order and
+// concurrency. tests drive the real Scheduler without real sleeping, eg:
+//
+//	h := dagtest.New[any]()
+//	s := dagRun.NewScheduler[any]()
+//	_ = s.Submit(h.Task("A"), h.Task("B", dagtest.After("A"), dagtest.FailTimes(1, err)))
+//	_ = s.Run(ctx, nil)
+//	h.AssertOrder(t, "A", "B")
+package dagtest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	dagRun "github.com/ycl2018/dag-run"
+)
+
+// EventKind is kind of Event
+type EventKind string
+
+const (
+	EventStart EventKind = "start"
+	EventEnd   EventKind = "end"
+)
+
+// Event records an attempt of fake task starting or ending
+type Event struct {
+	Seq     int
+	Task    string
+	Kind    EventKind
+	Attempt int
+	Time    time.Time // time of the fake clock
+	Err     error
+}
+
+// Harness creates fake tasks and records what they did
+type Harness[T any] struct {
+	clock      *Clock
+	lock       sync.Mutex
+	events     []Event
+	attempts   map[string]int
+	running    int
+	maxRunning int
+	changed    chan struct{}
+}
+
+// New build a Harness with a fake clock, set it to scheduler by WithClock to drive timeouts
+func New[T any]() *Harness[T] {
+	return &Harness[T]{
+		clock:    NewClock(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)),
+		attempts: map[string]int{},
+		changed:  make(chan struct{}),
+	}
+}
+
+// Clock return the fake clock of harness
+func (h *Harness[T]) Clock() *Clock {
+	return h.clock
+}
+
+// Script scripts the behavior of a fake task
+type Script func(*script)
+
+type script struct {
+	deps      []string
+	options   []dagRun.TaskOption
+	failTimes int
+	err       error
+	panicVal  any
+	block     bool
+	takes     time.Duration
+}
+
+// After set dependencies of task
+func After(deps ...string) Script {
+	return func(s *script) {
+		s.deps = append(s.deps, deps...)
+	}
+}
+
+// Options set task options of task
+func Options(ops ...dagRun.TaskOption) Script {
+	return func(s *script) {
+		s.options = append(s.options, ops...)
+	}
+}
+
+// FailTimes let the first n attempts of task return err, and succeed after
+func FailTimes(n int, err error) Script {
+	return func(s *script) {
+		s.failTimes = n
+		s.err = err
+	}
+}
+
+// Fail let all attempts of task return err
+func Fail(err error) Script {
+	return FailTimes(int(^uint(0)>>1), err)
+}
+
+// Panic let task panic with v
+func Panic(v any) Script {
+	return func(s *script) {
+		s.panicVal = v
+	}
+}
+
+// Block let task block until released by FakeTask.Release or ctx done
+func Block() Script {
+	return func(s *script) {
+		s.block = true
+	}
+}
+
+// Takes let each attempt of task sleep d on the fake clock, it's driven by Clock.Advance
+func Takes(d time.Duration) Script {
+	return func(s *script) {
+		s.takes = d
+	}
+}
+
+// FakeTask is a task with scripted outcome
+type FakeTask[T any] struct {
+	h           *Harness[T]
+	name        string
+	s           script
+	startOnce   sync.Once
+	started     chan struct{}
+	releaseOnce sync.Once
+	release     chan struct{}
+}
+
+// Task build a fake task which succeeds by default
+func (h *Harness[T]) Task(name string, scripts ...Script) *FakeTask[T] {
+	f := &FakeTask[T]{h: h, name: name, started: make(chan struct{}), release: make(chan struct{})}
+	for _, s := range scripts {
+		s(&f.s)
+	}
+	return f
+}
+
+func (f *FakeTask[T]) Name() string {
+	return f.name
+}
+
+func (f *FakeTask[T]) Dependencies() []string {
+	return f.s.deps
+}
+
+func (f *FakeTask[T]) Options() []dagRun.TaskOption {
+	return f.s.options
+}
+
+// Execute runs the script of task and records events
+func (f *FakeTask[T]) Execute(ctx context.Context, _ T) (err error) {
+	attempt := f.h.begin(f.name)
+	defer func() {
+		if r := recover(); r != nil {
+			f.h.end(f.name, attempt, fmt.Errorf("panic:%v", r))
+			panic(r)
+		}
+		f.h.end(f.name, attempt, err)
+	}()
+	f.startOnce.Do(func() { close(f.started) })
+	if f.s.takes > 0 {
+		timer := f.h.clock.NewTimer(f.s.takes)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	if f.s.block {
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if f.s.panicVal != nil {
+		panic(f.s.panicVal)
+	}
+	if attempt <= f.s.failTimes {
+		return f.s.err
+	}
+	return nil
+}
+
+// Started return a channel closed when the first attempt of task started
+func (f *FakeTask[T]) Started() <-chan struct{} {
+	return f.started
+}
+
+// Release unblock the task scripted by Block
+func (f *FakeTask[T]) Release() {
+	f.releaseOnce.Do(func() { close(f.release) })
+}
+
+// Attempts return how many times task executed
+func (f *FakeTask[T]) Attempts() int {
+	f.h.lock.Lock()
+	defer f.h.lock.Unlock()
+	return f.h.attempts[f.name]
+}
+
+// FakeBranch is a fake branch task
+type FakeBranch[T any] struct {
+	*FakeTask[T]
+	valid bool
+}
+
+// Branch build a fake branch task, tasks after it are skipped if valid is false
+func (h *Harness[T]) Branch(name string, valid bool, scripts ...Script) *FakeBranch[T] {
+	return &FakeBranch[T]{FakeTask: h.Task(name, scripts...), valid: valid}
+}
+
+func (b *FakeBranch[T]) ValidBranch(context.Context, T) bool {
+	return b.valid
+}
+
+func (h *Harness[T]) begin(name string) int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.attempts[name]++
+	h.running++
+	if h.running > h.maxRunning {
+		h.maxRunning = h.running
+	}
+	h.record(Event{Task: name, Kind: EventStart, Attempt: h.attempts[name]})
+	return h.attempts[name]
+}
+
+func (h *Harness[T]) end(name string, attempt int, err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.running--
+	h.record(Event{Task: name, Kind: EventEnd, Attempt: attempt, Err: err})
+}
+
+// record must be called with lock held
+func (h *Harness[T]) record(e Event) {
+	e.Seq = len(h.events)
+	e.Time = h.clock.Now()
+	h.events = append(h.events, e)
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
+
+// WaitRunning block until at least n fake tasks are executing at the same time
+func (h *Harness[T]) WaitRunning(n int) {
+	for {
+		h.lock.Lock()
+		if h.running >= n {
+			h.lock.Unlock()
+			return
+		}
+		changed := h.changed
+		h.lock.Unlock()
+		<-changed
+	}
+}
+
+// Events return all recorded events in order
+func (h *Harness[T]) Events() []Event {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]Event(nil), h.events...)
+}
+
+// Order return names of tasks in order of their first start
+func (h *Harness[T]) Order() []string {
+	var order []string
+	for _, e := range h.Events() {
+		if e.Kind == EventStart && e.Attempt == 1 {
+			order = append(order, e.Task)
+		}
+	}
+	return order
+}
+
+// MaxConcurrency return the max number of tasks executing at the same time
+func (h *Harness[T]) MaxConcurrency() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.maxRunning
+}
+
+// span return seq of first start and last end of task, -1 if not found
+func (h *Harness[T]) span(name string) (start, end int) {
+	start, end = -1, -1
+	for _, e := range h.Events() {
+		if e.Task != name {
+			continue
+		}
+		if e.Kind == EventStart && start < 0 {
+			start = e.Seq
+		}
+		if e.Kind == EventEnd {
+			end = e.Seq
+		}
+	}
+	return start, end
+}
+
+// AssertRan assert tasks executed
+func (h *Harness[T]) AssertRan(t testing.TB, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if start, _ := h.span(name); start < 0 {
+			t.Errorf("want task:%s ran but not", name)
+		}
+	}
+}
+
+// AssertNotRan assert tasks not executed
+func (h *Harness[T]) AssertNotRan(t testing.TB, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if start, _ := h.span(name); start >= 0 {
+			t.Errorf("want task:%s not ran but ran", name)
+		}
+	}
+}
+
+// AssertBefore assert task before finished before task after started
+func (h *Harness[T]) AssertBefore(t testing.TB, before, after string) {
+	t.Helper()
+	_, end := h.span(before)
+	start, _ := h.span(after)
+	if end < 0 || start < 0 || end > start {
+		t.Errorf("want task:%s finished before task:%s started, events:%v", before, after, h.Events())
+	}
+}
+
+// AssertOrder assert each task finished before the next one started
+func (h *Harness[T]) AssertOrder(t testing.TB, names ...string) {
+	t.Helper()
+	for i := 1; i < len(names); i++ {
+		h.AssertBefore(t, names[i-1], names[i])
+	}
+}
+
+// AssertConcurrent assert tasks a and b executed at the same time
+func (h *Harness[T]) AssertConcurrent(t testing.TB, a, b string) {
+	t.Helper()
+	aStart, aEnd := h.span(a)
+	bStart, bEnd := h.span(b)
+	// a task not ended is still running
+	if aEnd < aStart {
+		aEnd = int(^uint(0) >> 1)
+	}
+	if bEnd < bStart {
+		bEnd = int(^uint(0) >> 1)
+	}
+	if aStart < 0 || bStart < 0 || aEnd < bStart || bEnd < aStart {
+		t.Errorf("want task:%s and task:%s executed concurrently, events:%v", a, b, h.Events())
+	}
+}
+
+// AssertMaxConcurrency assert no more than n tasks executed at the same time
+func (h *Harness[T]) AssertMaxConcurrency(t testing.TB, n int) {
+	t.Helper()
+	if got := h.MaxConcurrency(); got > n {
+		t.Errorf("want max concurrency:%d but get:%d", n, got)
+	}
+}
@@ -0,0 +1,175 @@
+package dagtest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	dagRun "github.com/ycl2018/dag-run"
+)
+
+func TestOrderAndRetry(t *testing.T) {
+	h := New[any]()
+	s := dagRun.NewScheduler[any]()
+	b := h.Task("B", After("A"), Block(), FailTimes(2, errors.New("expect err in B")), Options(dagRun.Retry(3)))
+	d := h.Task("D", After("A"), Block())
+	if err := s.Submit(h.Task("A"), b, h.Task("C", After("B")), d); err != nil {
+		t.Fatal(err)
+	}
+	s.RunAsync(context.Background(), nil)
+	<-b.Started()
+	<-d.Started()
+	b.Release()
+	d.Release()
+	if err := s.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	h.AssertOrder(t, "A", "B", "C")
+	h.AssertBefore(t, "A", "D")
+	h.AssertConcurrent(t, "B", "D")
+	if b.Attempts() != 3 {
+		t.Errorf("want 3 attempts but get:%d", b.Attempts())
+	}
+	if order := h.Order(); order[0] != "A" || order[len(order)-1] != "C" {
+		t.Errorf("unexpected order:%v", order)
+	}
+}
+
+func TestGroupConcurrency(t *testing.T) {
+	h := New[any]()
+	s := dagRun.NewScheduler[any]().WithGroupConcurrency("io", 2)
+	var tasks []*FakeTask[any]
+	for _, name := range []string{"A", "B", "C", "D"} {
+		tasks = append(tasks, h.Task(name, Block(), Options(dagRun.Group("io"))))
+	}
+	for _, task := range tasks {
+		if err := s.Submit(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.RunAsync(context.Background(), nil)
+	h.WaitRunning(2)
+	for _, task := range tasks {
+		task.Release()
+	}
+	if err := s.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	h.AssertRan(t, "A", "B", "C", "D")
+	h.AssertMaxConcurrency(t, 2)
+}
+
+func TestFakeClock(t *testing.T) {
+	h := New[any]()
+	s := dagRun.NewScheduler[any]()
+	if err := s.Submit(h.Task("A", Takes(time.Hour)), h.Task("B", Takes(time.Hour)),
+		h.Task("C", After("A", "B"), Takes(time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+	start := h.Clock().Now()
+	s.RunAsync(context.Background(), nil)
+	h.Clock().BlockUntil(2)
+	h.Clock().Advance(time.Hour)
+	h.Clock().BlockUntil(1)
+	h.Clock().Advance(time.Minute)
+	if err := s.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	h.AssertConcurrent(t, "A", "B")
+	events := h.Events()
+	last := events[len(events)-1]
+	if last.Task != "C" || !last.Time.Equal(start.Add(time.Hour+time.Minute)) {
+		t.Errorf("unexpected last event:%+v", last)
+	}
+}
+
+func TestPanicAndBranch(t *testing.T) {
+	h := New[any]()
+	s := dagRun.NewScheduler[any]()
+	if err := s.Submit(h.Branch("B", false), h.Task("T1", After("B")), h.Task("T2", Panic("boom"))); err != nil {
+		t.Fatal(err)
+	}
+	err := s.Run(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("want panic err but get:%v", err)
+	}
+	h.AssertRan(t, "B", "T2")
+	h.AssertNotRan(t, "T1")
+}
+
+func TestClockTimer(t *testing.T) {
+	c := NewClock(time.Unix(0, 0))
+	t1 := c.NewTimer(2 * time.Second)
+	t2 := c.NewTimer(time.Second)
+	t3 := c.NewTimer(time.Second)
+	if !t3.Stop() || t3.Stop() {
+		t.Error("want stop once")
+	}
+	c.Advance(time.Second)
+	select {
+	case <-t1.C():
+		t.Error("t1 should not fire")
+	case now := <-t2.C():
+		if !now.Equal(time.Unix(1, 0)) {
+			t.Errorf("unexpected fire time:%v", now)
+		}
+	}
+	if c.Waiters() != 1 {
+		t.Errorf("want 1 waiter but get:%d", c.Waiters())
+	}
+	c.Advance(time.Second)
+	<-t1.C()
+	if t1.Stop() {
+		t.Error("fired timer should not stop")
+	}
+}
+
+func TestTimeoutOnFakeClock(t *testing.T) {
+	h := New[any]()
+	s := dagRun.NewScheduler[any]().WithClock(h.Clock())
+	if err := s.Submit(h.Task("A", Takes(time.Hour), Options(dagRun.Timeout(30*time.Minute)))); err != nil {
+		t.Fatal(err)
+	}
+	start := h.Clock().Now()
+	s.RunAsync(context.Background(), nil)
+	// timer of scheduler, timer of ctx and timer of task
+	h.Clock().BlockUntil(3)
+	h.Clock().Advance(30 * time.Minute)
+	if err := s.Wait(); !errors.Is(err, dagRun.ErrTaskTimeout) {
+		t.Fatalf("want timeout err but get:%v", err)
+	}
+	report := s.Report()
+	if !report.Start.Equal(start) || report.Duration() != 30*time.Minute {
+		t.Errorf("unexpected report start:%v duration:%v", report.Start, report.Duration())
+	}
+	// ctx of task canceled by the fake clock too
+	for _, e := range h.Events() {
+		if e.Kind == EventEnd && !errors.Is(e.Err, context.DeadlineExceeded) {
+			t.Errorf("want deadline exceeded but get:%v", e.Err)
+		}
+	}
+}
+
+func TestWaitTimeoutOnFakeClock(t *testing.T) {
+	h := New[any]()
+	a := h.Task("A", Block())
+	s := dagRun.NewScheduler[any]().WithClock(h.Clock())
+	if err := s.Submit(a); err != nil {
+		t.Fatal(err)
+	}
+	s.RunAsync(context.Background(), nil)
+	<-a.Started()
+	go func() {
+		h.Clock().BlockUntil(1)
+		h.Clock().Advance(time.Minute)
+	}()
+	if err := s.WaitTimeout(time.Minute); !errors.Is(err, dagRun.ErrTimeout) {
+		t.Fatalf("want wait timeout but get:%v", err)
+	}
+	a.Release()
+	if err := s.Wait(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,110 @@
+package dagtrace
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SpanData is a finished or running span recorded by Recorder
+type SpanData struct {
+	ID         int
+	ParentID   int // 0 for root span
+	Name       string
+	Attributes map[string]any
+	Err        error
+	Start      time.Time
+	End        time.Time
+}
+
+// Recorder is an in-memory Tracer which records all spans, useful in tests
+type Recorder struct {
+	lock  sync.Mutex
+	spans []*recordedSpan
+}
+
+// NewRecorder build an in-memory Tracer
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+type recordedSpan struct {
+	r    *Recorder
+	data SpanData
+}
+
+type spanKey struct{}
+
+// Start implements Tracer
+func (r *Recorder) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	span := &recordedSpan{r: r, data: SpanData{
+		ID:         len(r.spans) + 1,
+		Name:       name,
+		Attributes: map[string]any{},
+		Start:      time.Now(),
+	}}
+	if parent, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		span.data.ParentID = parent.data.ID
+	}
+	for _, attr := range attrs {
+		span.data.Attributes[attr.Key] = attr.Value
+	}
+	r.spans = append(r.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// ContextWithSpan implements Tracer
+func (r *Recorder) ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// Spans return all recorded spans in start order
+func (r *Recorder) Spans() []SpanData {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	spans := make([]SpanData, 0, len(r.spans))
+	for _, s := range r.spans {
+		data := s.data
+		data.Attributes = make(map[string]any, len(s.data.Attributes))
+		for k, v := range s.data.Attributes {
+			data.Attributes[k] = v
+		}
+		spans = append(spans, data)
+	}
+	return spans
+}
+
+// SpanFromContext return the data of span carried by ctx
+func (r *Recorder) SpanFromContext(ctx context.Context) (SpanData, bool) {
+	span, ok := ctx.Value(spanKey{}).(*recordedSpan)
+	if !ok {
+		return SpanData{}, false
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return span.data, true
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	s.r.lock.Lock()
+	defer s.r.lock.Unlock()
+	for _, attr := range attrs {
+		s.data.Attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.r.lock.Lock()
+	defer s.r.lock.Unlock()
+	s.data.Err = err
+}
+
+func (s *recordedSpan) End() {
+	s.r.lock.Lock()
+	defer s.r.lock.Unlock()
+	if s.data.End.IsZero() {
+		s.data.End = time.Now()
+	}
+}
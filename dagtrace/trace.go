@@ -0,0 +1,155 @@
+// Package dagtrace traces dagRun schedulers in OpenTelemetry style: a span for Scheduler.Run,
+// a child span for each task, and a child span of task for each attempt.
+// the span of attempt is propagated through the ctx passed to Task.Execute.
+package dagtrace
+
+import (
+	"context"
+	"sync"
+
+	dagRun "github.com/ycl2018/dag-run"
+)
+
+const (
+	RunSpanName     = "dag.run"
+	TaskSpanName    = "dag.task"
+	AttemptSpanName = "dag.attempt"
+)
+
+// Attribute keys of spans
+const (
+	KeyScheduler    = "dag.scheduler"
+	KeyTask         = "dag.task"
+	KeyDependencies = "dag.dependencies"
+	KeyBranchValid  = "dag.branch.valid"
+	KeyAttempt      = "dag.attempt"
+)
+
+// Attribute is a key-value pair of span
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr build an Attribute
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer start spans, it can be implemented on top of OpenTelemetry, see Recorder for an in-memory implementation
+type Tracer interface {
+	// Start a span as child of the span in ctx, return ctx carrying the new span
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+	// ContextWithSpan return ctx carrying span
+	ContextWithSpan(ctx context.Context, span Span) context.Context
+}
+
+// Span is a traced operation
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Instrument trace scheduler with tracer by its listener and middleware
+func Instrument[T any](s *dagRun.Scheduler[T], tracer Tracer) *dagRun.Scheduler[T] {
+	in := &instrument[T]{tracer: tracer, attempts: map[string]Span{}}
+	return s.WithListener(in).Use(in.middleware)
+}
+
+type instrument[T any] struct {
+	dagRun.NopListener
+	tracer   Tracer
+	lock     sync.Mutex
+	runSpan  Span
+	attempts map[string]Span
+}
+
+func (in *instrument[T]) OnRunStart(ctx context.Context, e dagRun.RunEvent) {
+	_, span := in.tracer.Start(ctx, RunSpanName, Attr(KeyScheduler, e.Scheduler))
+	in.lock.Lock()
+	in.runSpan = span
+	in.lock.Unlock()
+}
+
+func (in *instrument[T]) OnRunEnd(_ context.Context, e dagRun.RunEvent) {
+	in.lock.Lock()
+	span := in.runSpan
+	in.lock.Unlock()
+	if span == nil {
+		return
+	}
+	if e.Err != nil {
+		span.RecordError(e.Err)
+	}
+	span.End()
+}
+
+func (in *instrument[T]) OnTaskStart(ctx context.Context, e dagRun.TaskEvent) {
+	in.startAttempt(ctx, e)
+}
+
+func (in *instrument[T]) OnTaskRetry(ctx context.Context, e dagRun.TaskEvent) {
+	in.startAttempt(ctx, e)
+}
+
+// startAttempt end the span of last attempt and start a new one
+func (in *instrument[T]) startAttempt(ctx context.Context, e dagRun.TaskEvent) {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+	if last := in.attempts[e.Task]; last != nil {
+		if e.Err != nil {
+			last.RecordError(e.Err)
+		}
+		last.End()
+	}
+	_, span := in.tracer.Start(ctx, AttemptSpanName, Attr(KeyTask, e.Task), Attr(KeyAttempt, e.Attempt))
+	in.attempts[e.Task] = span
+}
+
+// AttemptContext implements dagRun.AttemptContexter, ctx of attempt carries its span
+func (in *instrument[T]) AttemptContext(ctx context.Context, e dagRun.TaskEvent) context.Context {
+	in.lock.Lock()
+	span := in.attempts[e.Task]
+	in.lock.Unlock()
+	if span == nil {
+		return ctx
+	}
+	return in.tracer.ContextWithSpan(ctx, span)
+}
+
+func (in *instrument[T]) endAttempt(task string, err error) {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+	if last := in.attempts[task]; last != nil {
+		if err != nil {
+			last.RecordError(err)
+		}
+		last.End()
+		delete(in.attempts, task)
+	}
+}
+
+func (in *instrument[T]) middleware(next dagRun.Handler[T]) dagRun.Handler[T] {
+	return func(ctx context.Context, task dagRun.Task[T], t T) error {
+		in.lock.Lock()
+		runSpan := in.runSpan
+		in.lock.Unlock()
+		if runSpan != nil {
+			ctx = in.tracer.ContextWithSpan(ctx, runSpan)
+		}
+		attrs := []Attribute{Attr(KeyTask, task.Name()), Attr(KeyDependencies, task.Dependencies())}
+		ctx, span := in.tracer.Start(ctx, TaskSpanName, attrs...)
+		defer span.End()
+		err := next(ctx, task, t)
+		in.endAttempt(task.Name(), err)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+		if ct, ok := task.(dagRun.Conditioned[T]); ok {
+			span.SetAttributes(Attr(KeyBranchValid, ct.ValidBranch(ctx, t)))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,95 @@
+package dagtrace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	dagRun "github.com/ycl2018/dag-run"
+)
+
+func TestInstrument(t *testing.T) {
+	recorder := NewRecorder()
+	s := Instrument(dagRun.NewScheduler[any]().WithName("trace"), recorder)
+	var tries int
+	var parentOfT2 SpanData
+	var parentsOfT1 []int
+	if err := s.SubmitFuncWithOps("T1", func(ctx context.Context, _ any) error {
+		tries++
+		parent, _ := recorder.SpanFromContext(ctx)
+		parentsOfT1 = append(parentsOfT1, parent.ID)
+		if tries < 2 {
+			return errors.New("expect err in T1")
+		}
+		return nil
+	}, []dagRun.TaskOption{dagRun.Retry(2)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SubmitBranchFunc("T2", func(ctx context.Context, _ any) (bool, error) {
+		parentOfT2, _ = recorder.SpanFromContext(ctx)
+		return false, nil
+	}, "T1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Run(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	spans := recorder.Spans()
+	type want struct {
+		name     string
+		parentID int
+		attrs    map[string]any
+		err      string
+	}
+	wants := []want{
+		{name: RunSpanName, attrs: map[string]any{KeyScheduler: "trace"}},
+		{name: TaskSpanName, parentID: 1, attrs: map[string]any{KeyTask: "T1"}},
+		{name: AttemptSpanName, parentID: 2, attrs: map[string]any{KeyTask: "T1", KeyAttempt: 1}, err: "expect err in T1"},
+		{name: AttemptSpanName, parentID: 2, attrs: map[string]any{KeyTask: "T1", KeyAttempt: 2}},
+		{name: TaskSpanName, parentID: 1, attrs: map[string]any{KeyTask: "T2", KeyBranchValid: false}},
+		{name: AttemptSpanName, parentID: 5, attrs: map[string]any{KeyTask: "T2", KeyAttempt: 1}},
+	}
+	if len(spans) != len(wants) {
+		t.Fatalf("want %d spans but get:%d, %+v", len(wants), len(spans), spans)
+	}
+	for i, w := range wants {
+		span := spans[i]
+		if span.Name != w.name || span.ParentID != w.parentID {
+			t.Errorf("span %d want:%s parent:%d but get:%s parent:%d", i, w.name, w.parentID, span.Name, span.ParentID)
+		}
+		for k, v := range w.attrs {
+			if span.Attributes[k] != v {
+				t.Errorf("span %d attr %s want:%v but get:%v", i, k, v, span.Attributes[k])
+			}
+		}
+		if (span.Err == nil) != (w.err == "") || (span.Err != nil && span.Err.Error() != w.err) {
+			t.Errorf("span %d want err:%s but get:%v", i, w.err, span.Err)
+		}
+		if span.End.IsZero() {
+			t.Errorf("span %d not ended", i)
+		}
+	}
+	// each attempt runs in its own span
+	if len(parentsOfT1) != 2 || parentsOfT1[0] != 3 || parentsOfT1[1] != 4 {
+		t.Errorf("want spans of attempts of T1 in ctx but get:%v", parentsOfT1)
+	}
+	if parentOfT2.ID != 6 || parentOfT2.Name != AttemptSpanName {
+		t.Errorf("want span of attempt of T2 in ctx but get:%+v", parentOfT2)
+	}
+}
+
+func TestInstrumentError(t *testing.T) {
+	recorder := NewRecorder()
+	s := Instrument(dagRun.NewScheduler[any](), recorder)
+	_ = s.SubmitFunc("T1", func(ctx context.Context, _ any) error {
+		return errors.New("expect err in T1")
+	})
+	if err := s.Run(context.Background(), nil); err == nil {
+		t.Fatal("want err but get nil")
+	}
+	for _, span := range recorder.Spans() {
+		if span.Err == nil || span.Err.Error() != "expect err in T1" {
+			t.Errorf("span %s want err but get:%v", span.Name, span.Err)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package dagRun
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Inspectable is a scheduler which can be inspected by debug handler,
+// both Scheduler and FuncScheduler implement it
+type Inspectable interface {
+	Name() string
+	Dot(ops ...DotOption) string
+	Mermaid() string
+	JSON() ([]byte, error)
+	Report() *RunReport
+}
+
+// DebugRegistry keeps recent registered schedulers and serves their state over http:
+//
+//	/                          list all schedulers
+//	/running                   list running schedulers with status of each task
+//	/graph?id=1&format=dot     graph of scheduler, format can be json(default), dot or mermaid
+//	/report?id=1               run report of scheduler
+//	/reports?name=x            recent finished run reports, newest first, filtered by name if given
+type DebugRegistry struct {
+	lock    sync.Mutex
+	max     int
+	seq     int
+	entries []debugEntry
+}
+
+type debugEntry struct {
+	id int
+	s  Inspectable
+}
+
+// NewDebugRegistry build a DebugRegistry which keeps at most maxEntries schedulers. when full,
+// the oldest finished or failed one is evicted, then the oldest pending one. running ones are
+// never evicted, and the registry grows beyond maxEntries if all of them are running
+func NewDebugRegistry(maxEntries int) *DebugRegistry {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+	return &DebugRegistry{max: maxEntries}
+}
+
+var defaultDebugRegistry = NewDebugRegistry(100)
+
+// RegisterDebug register scheduler to the default DebugRegistry, return its id
+func RegisterDebug(s Inspectable) int {
+	return defaultDebugRegistry.Register(s)
+}
+
+// DebugHandler return the http handler of default DebugRegistry, it can be mounted like:
+//
+//	http.Handle("/debug/dag/", dagRun.DebugHandler())
+func DebugHandler() http.Handler {
+	return defaultDebugRegistry
+}
+
+// Register add scheduler to registry, return its id
+func (r *DebugRegistry) Register(s Inspectable) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for len(r.entries) >= r.max {
+		evict := r.evictable()
+		if evict < 0 {
+			break
+		}
+		r.entries = append(r.entries[:evict], r.entries[evict+1:]...)
+	}
+	r.seq++
+	r.entries = append(r.entries, debugEntry{id: r.seq, s: s})
+	return r.seq
+}
+
+// evictable return index of the entry to evict, -1 if all are running
+func (r *DebugRegistry) evictable() int {
+	pending := -1
+	for i, e := range r.entries {
+		switch debugState(e.s.Report()) {
+		case "finished", "failed":
+			return i
+		case "pending":
+			if pending < 0 {
+				pending = i
+			}
+		}
+	}
+	return pending
+}
+
+func (r *DebugRegistry) list() []debugEntry {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]debugEntry(nil), r.entries...)
+}
+
+func (r *DebugRegistry) get(id string) (Inspectable, bool) {
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, false
+	}
+	for _, e := range r.list() {
+		if e.id == i {
+			return e.s, true
+		}
+	}
+	return nil, false
+}
+
+func debugState(report *RunReport) string {
+	switch {
+	case report.Start.IsZero():
+		return "pending"
+	case report.End.IsZero():
+		return "running"
+	case report.Err != nil:
+		return "failed"
+	default:
+		return "finished"
+	}
+}
+
+type debugSummary struct {
+	ID       int                `json:"id"`
+	Name     string             `json:"name"`
+	RunID    string             `json:"run_id,omitempty"`
+	State    string             `json:"state"`
+	Start    time.Time          `json:"start,omitempty"`
+	End      time.Time          `json:"end,omitempty"`
+	Statuses map[TaskStatus]int `json:"statuses"`
+	Report   *reportJSON        `json:"report,omitempty"`
+}
+
+func newDebugSummary(e debugEntry, report *RunReport) debugSummary {
+	ds := debugSummary{ID: e.id, Name: e.s.Name(), RunID: report.RunID, State: debugState(report),
+		Start: report.Start, End: report.End, Statuses: map[TaskStatus]int{}}
+	for _, t := range report.Tasks {
+		ds.Statuses[t.Status]++
+	}
+	return ds
+}
+
+// ServeHTTP implements http.Handler
+func (r *DebugRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	action := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+	query := req.URL.Query()
+	switch action {
+	case "":
+		var summaries = []debugSummary{}
+		for _, e := range r.list() {
+			summaries = append(summaries, newDebugSummary(e, e.s.Report()))
+		}
+		writeDebugJSON(w, summaries)
+	case "running":
+		var summaries = []debugSummary{}
+		for _, e := range r.list() {
+			report := e.s.Report()
+			if debugState(report) == "running" {
+				ds := newDebugSummary(e, report)
+				ds.Report = newReportJSON(report)
+				summaries = append(summaries, ds)
+			}
+		}
+		writeDebugJSON(w, summaries)
+	case "reports":
+		var summaries = []debugSummary{}
+		for _, e := range r.list() {
+			if name := query.Get("name"); name != "" && e.s.Name() != name {
+				continue
+			}
+			report := e.s.Report()
+			if state := debugState(report); state == "finished" || state == "failed" {
+				ds := newDebugSummary(e, report)
+				ds.Report = newReportJSON(report)
+				summaries = append(summaries, ds)
+			}
+		}
+		sort.SliceStable(summaries, func(i, j int) bool {
+			return summaries[i].End.After(summaries[j].End)
+		})
+		writeDebugJSON(w, summaries)
+	case "report":
+		s, ok := r.get(query.Get("id"))
+		if !ok {
+			http.Error(w, "scheduler not found", http.StatusNotFound)
+			return
+		}
+		writeDebugJSON(w, newReportJSON(s.Report()))
+	case "graph":
+		s, ok := r.get(query.Get("id"))
+		if !ok {
+			http.Error(w, "scheduler not found", http.StatusNotFound)
+			return
+		}
+		switch query.Get("format") {
+		case "dot":
+			w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+			_, _ = w.Write([]byte(s.Dot()))
+		case "mermaid":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte(s.Mermaid()))
+		case "", "json":
+			data, err := s.JSON()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(data)
+		default:
+			http.Error(w, "unknown format", http.StatusBadRequest)
+		}
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func writeDebugJSON(w http.ResponseWriter, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,119 @@
+package dagRun
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugHandler(t *testing.T) {
+	registry := NewDebugRegistry(2)
+	release := make(chan struct{})
+	running := NewFuncScheduler().WithName("running").
+		Submit("T1", func() error { <-release; return nil })
+	finished := exportScheduler().WithName("finished")
+	checkNil(t, finished.Run())
+	registry.Register(NewFuncScheduler().WithName("evicted"))
+	runningID := registry.Register(running)
+	finishedID := registry.Register(finished)
+	go func() { _ = running.Run() }()
+	defer close(release)
+	for running.Report().Start.IsZero() {
+		time.Sleep(time.Millisecond)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/dag/", registry)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	var get = func(path string) (int, string) {
+		resp, err := http.Get(server.URL + "/debug/dag/" + path)
+		checkNil(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		checkNil(t, err)
+		return resp.StatusCode, string(data)
+	}
+
+	var summaries []debugSummary
+	_, body := get("")
+	checkNil(t, json.Unmarshal([]byte(body), &summaries))
+	checkEqual(t, 2, len(summaries))
+	checkEqual(t, "running", summaries[0].Name)
+	checkEqual(t, "running", summaries[0].State)
+	checkEqual(t, "finished", summaries[1].State)
+	checkEqual(t, 4, summaries[1].Statuses[TaskSuccess])
+
+	_, body = get("running")
+	summaries = nil
+	checkNil(t, json.Unmarshal([]byte(body), &summaries))
+	checkEqual(t, 1, len(summaries))
+	checkEqual(t, runningID, summaries[0].ID)
+	checkEqual(t, "running", summaries[0].Report.Tasks[0].Status)
+
+	_, body = get("reports?name=finished")
+	summaries = nil
+	checkNil(t, json.Unmarshal([]byte(body), &summaries))
+	checkEqual(t, 1, len(summaries))
+	checkEqual(t, finishedID, summaries[0].ID)
+
+	id := "?id=" + strconv.Itoa(finishedID)
+	code, body := get("graph" + id + "&format=mermaid")
+	checkEqual(t, http.StatusOK, code)
+	checkEqual(t, finished.Mermaid(), body)
+	_, body = get("graph" + id + "&format=dot")
+	checkEqual(t, finished.Dot(), body)
+	_, body = get("graph" + id)
+	checkEqual(t, true, strings.Contains(body, `"nodes"`))
+	_, body = get("report" + id)
+	checkEqual(t, true, strings.Contains(body, `"status": "success"`))
+
+	code, _ = get("graph?id=100")
+	checkEqual(t, http.StatusNotFound, code)
+	code, _ = get("graph" + id + "&format=png")
+	checkEqual(t, http.StatusBadRequest, code)
+	code, _ = get("unknown")
+	checkEqual(t, http.StatusNotFound, code)
+}
+
+func TestDebugRegistryEvict(t *testing.T) {
+	registry := NewDebugRegistry(2)
+	release := make(chan struct{})
+	defer close(release)
+	var run = func(name string) *FuncScheduler {
+		s := NewFuncScheduler().WithName(name).Submit("T1", func() error { <-release; return nil })
+		go func() { _ = s.Run() }()
+		for s.Report().Start.IsZero() {
+			time.Sleep(time.Millisecond)
+		}
+		return s
+	}
+	var names = func() []string {
+		var names []string
+		for _, e := range registry.list() {
+			names = append(names, e.s.Name())
+		}
+		return names
+	}
+	finished := NewFuncScheduler().WithName("finished")
+	checkNil(t, finished.Run())
+
+	registry.Register(NewFuncScheduler().WithName("pending"))
+	registry.Register(finished)
+	// finished is evicted before the older pending one
+	registry.Register(run("running1"))
+	checkEqual(t, "[pending running1]", fmt.Sprint(names()))
+	registry.Register(run("running2"))
+	checkEqual(t, "[running1 running2]", fmt.Sprint(names()))
+	// running ones are never evicted
+	registry.Register(NewFuncScheduler().WithName("new"))
+	checkEqual(t, "[running1 running2 new]", fmt.Sprint(names()))
+	registry.Register(NewFuncScheduler().WithName("newer"))
+	checkEqual(t, "[running1 running2 newer]", fmt.Sprint(names()))
+}
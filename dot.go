@@ -13,6 +13,7 @@ type dotContext struct {
 	EdgeCommonAttr map[string]string
 	NodeAttr       map[string]map[string]string
 	EdgeAttr       map[string]map[string]string // key: from->to
+	Clusters       map[string][]string          // key: cluster label
 }
 
 const StartNodeName = "start"
@@ -24,6 +25,11 @@ func (d dummyNode) Name() string {
 	return string(d)
 }
 
+// dotQuote quote s as a DOT string
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
 func genDot(ctx *dotContext) string {
 	var sb strings.Builder
 	sb.WriteString("digraph G {\n")
@@ -60,7 +66,7 @@ func genDot(ctx *dotContext) string {
 		if len(ctx.NodeAttr) == 0 || len(ctx.NodeAttr[nodeName]) == 0 {
 			return
 		}
-		showName := "\"" + nodeName + "\""
+		showName := dotQuote(nodeName)
 		sb.WriteString(showName + " [")
 		var startAttrs []string
 		for k, v := range ctx.NodeAttr[nodeName] {
@@ -86,6 +92,26 @@ func genDot(ctx *dotContext) string {
 			writeNodeAttr(k, false)
 		}
 	}
+	// define clusters
+	if len(ctx.Clusters) > 0 {
+		var labels []string
+		for k := range ctx.Clusters {
+			labels = append(labels, k)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			sb.WriteString("\n")
+			sb.WriteString("subgraph " + dotQuote("cluster_"+label) + " {\n")
+			sb.WriteString("label=" + dotQuote(label) + "\n")
+			var nodeNames []string
+			for _, name := range ctx.Clusters[label] {
+				nodeNames = append(nodeNames, dotQuote(name))
+			}
+			sort.Strings(nodeNames)
+			sb.WriteString(strings.Join(nodeNames, ";"))
+			sb.WriteString("\n}\n")
+		}
+	}
 	// define common edge attributes
 	if len(ctx.EdgeCommonAttr) > 0 {
 		sb.WriteString("\n")
@@ -109,12 +135,12 @@ func genDot(ctx *dotContext) string {
 		sort.Slice(edgeStarts, func(i, j int) bool { return edgeStarts[i].Name() < edgeStarts[j].Name() })
 		for _, edgeStart := range edgeStarts {
 			startName := edgeStart.Name()
-			sb.WriteString("\"" + startName + "\"")
+			sb.WriteString(dotQuote(startName))
 			sb.WriteString(" -> {")
 			var toNodesNames []string
 			toNodes := ctx.Edges[edgeStart]
 			for _, to := range toNodes {
-				toNodesNames = append(toNodesNames, "\""+to.Name()+"\"")
+				toNodesNames = append(toNodesNames, dotQuote(to.Name()))
 			}
 			sort.Strings(toNodesNames)
 			sb.WriteString(strings.Join(toNodesNames, ","))
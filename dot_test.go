@@ -1,7 +1,9 @@
 package dagRun
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -13,3 +15,15 @@ func TestDot(t *testing.T) {
 	),
 	)
 }
+
+func TestDotEscape(t *testing.T) {
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitFuncWithOps(`T"1`, func(ctx context.Context, _ any) error { return nil },
+		[]TaskOption{Group(`g"1\`)}))
+	dot := ds.Dot()
+	for _, want := range []string{`subgraph "cluster_g\"1\\" {`, `label="g\"1\\"`, `"T\"1"`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("want %s in dot:\n%s", want, dot)
+		}
+	}
+}
@@ -3,12 +3,20 @@ package dagRun
 import "errors"
 
 var (
-	ErrNilTask      = errors.New("dagRun: nil task")
-	ErrTaskExist    = errors.New("dagRun: task already exist")
-	ErrNoTaskName   = errors.New("dagRun: no task name")
-	ErrNilFunc      = errors.New("dagRun: nil func")
-	ErrTaskNotExist = errors.New("dagRun: task not found")
-	ErrSealed       = errors.New("dagRun: dag is sealed")
-	ErrNotAsyncJob  = errors.New("dagRun: not async job")
-	ErrTimeout      = errors.New("dagRun: wait timeout")
+	ErrNilTask       = errors.New("dagRun: nil task")
+	ErrTaskExist     = errors.New("dagRun: task already exist")
+	ErrNoTaskName    = errors.New("dagRun: no task name")
+	ErrNilFunc       = errors.New("dagRun: nil func")
+	ErrTaskNotExist  = errors.New("dagRun: task not found")
+	ErrSealed        = errors.New("dagRun: dag is sealed")
+	ErrNotAsyncJob   = errors.New("dagRun: not async job")
+	ErrTimeout       = errors.New("dagRun: wait timeout")
+	ErrTaskTimeout   = errors.New("dagRun: task run timeout")
+	ErrSkipped       = errors.New("dagRun: task skipped by branch")
+	ErrCanceled      = errors.New("dagRun: task canceled")
+	ErrInvalidOption = errors.New("dagRun: invalid task option")
+	ErrNoStateStore  = errors.New("dagRun: no state store")
+	ErrNotRunning    = errors.New("dagRun: scheduler not running")
+	ErrLoopLimit     = errors.New("dagRun: loop reached limit")
+	ErrCircuitOpen   = errors.New("dagRun: circuit breaker is open")
 )
@@ -0,0 +1,289 @@
+package dagRun
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// graphView is the model of scheduler graph shared by all exports, nodes and edges are sorted
+type graphView struct {
+	Nodes []viewNode
+	Edges []viewEdge
+}
+
+type viewNode struct {
+	Name    string
+	Group   string
+	Branch  bool
+	Retry   int
+	Timeout time.Duration
+	Sub     *graphView // graph of the sub dag
+}
+
+type viewEdge struct {
+	From string
+	To   string
+}
+
+func (d *Scheduler[T]) view() graphView {
+	var v graphView
+	// sub views are built out of lock, as building a loop body calls user code
+	var subs = map[string]subViewer{}
+	d.lock.Lock()
+	for _, n := range d.nodes {
+		_, branch := n.task.(Conditioned[T])
+		vn := viewNode{
+			Name:    n.Name(),
+			Group:   n.opt.group,
+			Branch:  branch,
+			Retry:   n.opt.retry,
+			Timeout: n.opt.timeout,
+		}
+		if s, ok := n.task.(subViewer); ok {
+			subs[n.Name()] = s
+		}
+		v.Nodes = append(v.Nodes, vn)
+		for _, dep := range n.task.Dependencies() {
+			if _, ok := d.nodes[dep]; ok {
+				v.Edges = append(v.Edges, viewEdge{From: dep, To: n.Name()})
+			}
+		}
+	}
+	d.lock.Unlock()
+	for i, n := range v.Nodes {
+		if s, ok := subs[n.Name]; ok {
+			sub := s.subView()
+			v.Nodes[i].Sub = &sub
+		}
+	}
+	sort.Slice(v.Nodes, func(i, j int) bool {
+		return v.Nodes[i].Name < v.Nodes[j].Name
+	})
+	sort.Slice(v.Edges, func(i, j int) bool {
+		if v.Edges[i].From != v.Edges[j].From {
+			return v.Edges[i].From < v.Edges[j].From
+		}
+		return v.Edges[i].To < v.Edges[j].To
+	})
+	return v
+}
+
+// groups return group names in order and the index of node in v.Nodes by group
+func (v graphView) groups() ([]string, map[string][]int) {
+	var names []string
+	var members = map[string][]int{}
+	for i, n := range v.Nodes {
+		if n.Group == "" {
+			continue
+		}
+		if _, ok := members[n.Group]; !ok {
+			names = append(names, n.Group)
+		}
+		members[n.Group] = append(members[n.Group], i)
+	}
+	sort.Strings(names)
+	return names, members
+}
+
+func (v graphView) graph() *Graph {
+	g := NewGraph()
+	v.addTo(g, "")
+	return g
+}
+
+// addTo add nodes and edges to g, names are prefixed with prefix.
+// nodes of sub dag are prefixed with name of sub dag node, which has edges to them
+func (v graphView) addTo(g *Graph, prefix string) {
+	for _, n := range v.Nodes {
+		g.AddNode(dummyNode(prefix + n.Name))
+	}
+	for _, e := range v.Edges {
+		g.AddEdge(dummyNode(prefix+e.From), dummyNode(prefix+e.To))
+	}
+	for _, n := range v.Nodes {
+		if n.Sub == nil {
+			continue
+		}
+		subPrefix := prefix + n.Name + "/"
+		n.Sub.addTo(g, subPrefix)
+		hasPre := map[string]bool{}
+		for _, e := range n.Sub.Edges {
+			hasPre[e.To] = true
+		}
+		for _, sn := range n.Sub.Nodes {
+			if !hasPre[sn.Name] {
+				g.AddEdge(dummyNode(prefix+n.Name), dummyNode(subPrefix+sn.Name))
+			}
+		}
+	}
+}
+
+func (v graphView) dotOptions() []DotOption {
+	ops := v.nodeDotOptions("")
+	names, members := v.groups()
+	for _, group := range names {
+		var nodeNames []string
+		for _, i := range members[group] {
+			nodeNames = append(nodeNames, v.Nodes[i].Name)
+		}
+		ops = append(ops, WithCluster(group, nodeNames...))
+	}
+	return ops
+}
+
+// nodeDotOptions return attributes of nodes prefixed with prefix, and clusters of sub dags
+func (v graphView) nodeDotOptions(prefix string) []DotOption {
+	var ops []DotOption
+	for _, n := range v.Nodes {
+		if n.Branch {
+			ops = append(ops, WithNodeAttr(prefix+n.Name, "shape=diamond", `color="blue"`))
+		}
+		if n.Sub == nil {
+			continue
+		}
+		subPrefix := prefix + n.Name + "/"
+		ops = append(ops, WithNodeAttr(prefix+n.Name, "shape=box3d"))
+		var nodeNames []string
+		for _, sn := range n.Sub.Nodes {
+			nodeNames = append(nodeNames, subPrefix+sn.Name)
+		}
+		ops = append(ops, WithCluster(prefix+n.Name, nodeNames...))
+		ops = append(ops, n.Sub.nodeDotOptions(subPrefix)...)
+	}
+	return ops
+}
+
+// index return the index of node names, used as ids in exports
+func (v graphView) index() map[string]int {
+	idx := make(map[string]int, len(v.Nodes))
+	for i, n := range v.Nodes {
+		idx[n.Name] = i
+	}
+	return idx
+}
+
+func (v graphView) mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	var writeNode = func(indent string, i int) {
+		n := v.Nodes[i]
+		label := strings.ReplaceAll(n.Name, `"`, "#quot;")
+		sb.WriteString(indent + "n" + strconv.Itoa(i))
+		if n.Branch {
+			sb.WriteString(`{"` + label + `"}`)
+		} else {
+			sb.WriteString(`["` + label + `"]`)
+		}
+		sb.WriteString("\n")
+	}
+	for i, n := range v.Nodes {
+		if n.Group == "" {
+			writeNode("    ", i)
+		}
+	}
+	names, members := v.groups()
+	for gi, group := range names {
+		label := strings.ReplaceAll(group, `"`, "#quot;")
+		sb.WriteString("    subgraph g" + strconv.Itoa(gi) + ` ["` + label + `"]` + "\n")
+		for _, i := range members[group] {
+			writeNode("        ", i)
+		}
+		sb.WriteString("    end\n")
+	}
+	idx := v.index()
+	for _, e := range v.Edges {
+		sb.WriteString("    n" + strconv.Itoa(idx[e.From]) + " --> n" + strconv.Itoa(idx[e.To]) + "\n")
+	}
+	return sb.String()
+}
+
+func (v graphView) plantUML() string {
+	var sb strings.Builder
+	sb.WriteString("@startuml\n")
+	var writeNode = func(indent string, i int) {
+		n := v.Nodes[i]
+		sb.WriteString(indent + "rectangle " + strconv.Quote(n.Name))
+		if n.Branch {
+			sb.WriteString(" <<branch>>")
+		}
+		sb.WriteString(" as n" + strconv.Itoa(i) + "\n")
+	}
+	for i, n := range v.Nodes {
+		if n.Group == "" {
+			writeNode("", i)
+		}
+	}
+	names, members := v.groups()
+	for _, group := range names {
+		sb.WriteString("package " + strconv.Quote(group) + " {\n")
+		for _, i := range members[group] {
+			writeNode("  ", i)
+		}
+		sb.WriteString("}\n")
+	}
+	idx := v.index()
+	for _, e := range v.Edges {
+		sb.WriteString("n" + strconv.Itoa(idx[e.From]) + " --> n" + strconv.Itoa(idx[e.To]) + "\n")
+	}
+	sb.WriteString("@enduml")
+	return sb.String()
+}
+
+// GraphJSON is the json model of scheduler graph
+type GraphJSON struct {
+	Nodes []NodeJSON `json:"nodes"`
+	Edges []EdgeJSON `json:"edges"`
+}
+
+// NodeJSON is the json model of task
+type NodeJSON struct {
+	Name    string      `json:"name"`
+	Group   string      `json:"group,omitempty"`
+	Branch  bool        `json:"branch"`
+	Options OptionsJSON `json:"options"`
+}
+
+// OptionsJSON is the json model of task options
+type OptionsJSON struct {
+	Retry   int    `json:"retry,omitempty"`
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// EdgeJSON is the json model of dependency, From is the dependency of To
+type EdgeJSON struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (v graphView) json() GraphJSON {
+	g := GraphJSON{Nodes: make([]NodeJSON, 0, len(v.Nodes)), Edges: make([]EdgeJSON, 0, len(v.Edges))}
+	for _, n := range v.Nodes {
+		nj := NodeJSON{Name: n.Name, Group: n.Group, Branch: n.Branch, Options: OptionsJSON{Retry: n.Retry}}
+		if n.Timeout > 0 {
+			nj.Options.Timeout = n.Timeout.String()
+		}
+		g.Nodes = append(g.Nodes, nj)
+	}
+	for _, e := range v.Edges {
+		g.Edges = append(g.Edges, EdgeJSON{From: e.From, To: e.To})
+	}
+	return g
+}
+
+// Mermaid dump dag in mermaid flowchart
+func (d *Scheduler[T]) Mermaid() string {
+	return d.view().mermaid()
+}
+
+// PlantUML dump dag in plantUML
+func (d *Scheduler[T]) PlantUML() string {
+	return d.view().plantUML()
+}
+
+// JSON dump dag in json, see GraphJSON
+func (d *Scheduler[T]) JSON() ([]byte, error) {
+	return json.MarshalIndent(d.view().json(), "", "  ")
+}
@@ -0,0 +1,112 @@
+package dagRun
+
+import (
+	"testing"
+	"time"
+)
+
+func exportScheduler() *FuncScheduler {
+	var nop = func() error { return nil }
+	return NewFuncScheduler().
+		SubmitWithOps("A", nop, []TaskOption{Group("fetch"), Retry(3)}).
+		SubmitWithOps("B", nop, []TaskOption{Group("fetch"), Timeout(time.Second)}).
+		SubmitBranch("C", func() (bool, error) { return true, nil }, "A").
+		Submit("D", nop, "B", "C")
+}
+
+func TestMermaid(t *testing.T) {
+	checkEqual(t, `flowchart TD
+    n2{"C"}
+    n3["D"]
+    subgraph g0 ["fetch"]
+        n0["A"]
+        n1["B"]
+    end
+    n0 --> n2
+    n1 --> n3
+    n2 --> n3
+`, exportScheduler().Mermaid())
+}
+
+func TestPlantUML(t *testing.T) {
+	checkEqual(t, `@startuml
+rectangle "C" <<branch>> as n2
+rectangle "D" as n3
+package "fetch" {
+  rectangle "A" as n0
+  rectangle "B" as n1
+}
+n0 --> n2
+n1 --> n3
+n2 --> n3
+@enduml`, exportScheduler().PlantUML())
+}
+
+func TestJSON(t *testing.T) {
+	data, err := exportScheduler().JSON()
+	checkNil(t, err)
+	checkEqual(t, `{
+  "nodes": [
+    {
+      "name": "A",
+      "group": "fetch",
+      "branch": false,
+      "options": {
+        "retry": 3
+      }
+    },
+    {
+      "name": "B",
+      "group": "fetch",
+      "branch": false,
+      "options": {
+        "timeout": "1s"
+      }
+    },
+    {
+      "name": "C",
+      "branch": true,
+      "options": {}
+    },
+    {
+      "name": "D",
+      "branch": false,
+      "options": {}
+    }
+  ],
+  "edges": [
+    {
+      "from": "A",
+      "to": "C"
+    },
+    {
+      "from": "B",
+      "to": "D"
+    },
+    {
+      "from": "C",
+      "to": "D"
+    }
+  ]
+}`, string(data))
+}
+
+func TestDotBeforeRun(t *testing.T) {
+	checkEqual(t, `digraph G {
+
+"start" [color="green",shape=doublecircle]
+"end" [color="red",shape=doublecircle]
+"C" [color="blue",shape=diamond]
+
+subgraph "cluster_fetch" {
+label="fetch"
+"A";"B"
+}
+
+"A" -> {"C"}
+"B" -> {"D"}
+"C" -> {"D"}
+"D" -> {"end"}
+"start" -> {"A","B"}
+}`, exportScheduler().Dot())
+}
@@ -0,0 +1,55 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Fallback set the fallback of task, which is called with the final error of task after retries,
+// eg: use stale cache when live fetch fails. if it returns nil, the dag continues as if task succeeded,
+// with status TaskFallback and the error of task in report.
+// the type T must be the same with the scheduler which task is submitted to
+func Fallback[T any](f func(ctx context.Context, t T, err error) error) TaskOption {
+	return func(o *option) {
+		o.fallback = f
+	}
+}
+
+// FallbackFunc set the fallback of task without runCtx, eg: for tasks of FuncScheduler
+func FallbackFunc(f func(ctx context.Context, err error) error) TaskOption {
+	return func(o *option) {
+		o.fallback = f
+	}
+}
+
+// checkFallback check type of fallback set by option
+func checkFallback[T any](name string, o option) error {
+	switch o.fallback.(type) {
+	case nil, func(context.Context, T, error) error, func(context.Context, error) error:
+		return nil
+	default:
+		return fmt.Errorf("%w: task:%s fallback type:%T", ErrInvalidOption, name, o.fallback)
+	}
+}
+
+// runFallback call fallback of node with err of task, return nil if fallback succeeded
+func (n *node[T]) runFallback(ctx context.Context, t T, err error) error {
+	var fErr error
+	switch f := n.opt.fallback.(type) {
+	case func(context.Context, T, error) error:
+		fErr = f(ctx, t, err)
+	case func(context.Context, error) error:
+		fErr = f(ctx, err)
+	default:
+		return err
+	}
+	if fErr != nil {
+		return errors.Join(err, fmt.Errorf("dag: fallback of task:%s err:%w", n.Name(), fErr))
+	}
+	n.mu.Lock()
+	n.report.Err = err
+	n.mu.Unlock()
+	n.fellBack = true
+	return nil
+}
@@ -0,0 +1,51 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFallback(t *testing.T) {
+	var tries int
+	var after bool
+	ds := NewScheduler[*counterCtx]()
+	checkNil(t, ds.SubmitFuncWithOps("fetch", func(ctx context.Context, x *counterCtx) error {
+		tries++
+		return errors.New("expect err in fetch")
+	}, []TaskOption{Retry(2), Fallback(func(ctx context.Context, x *counterCtx, err error) error {
+		// use stale value
+		x.set("fetch", -1)
+		return nil
+	})}))
+	checkNil(t, ds.SubmitFunc("after", func(ctx context.Context, x *counterCtx) error {
+		after = true
+		return nil
+	}, "fetch"))
+	x := newCounterCtx()
+	checkNil(t, ds.Run(context.Background(), x))
+	checkEqual(t, 2, tries)
+	checkEqual(t, true, after)
+	checkEqual(t, -1, x.outputs["fetch"])
+	tr, _ := ds.Report().Task("fetch")
+	checkEqual(t, TaskFallback, tr.Status)
+	checkEqual(t, "expect err in fetch", tr.Err.Error())
+}
+
+func TestFallbackErr(t *testing.T) {
+	ds := NewFuncScheduler()
+	ds.SubmitWithOps("fetch", func() error {
+		return errors.New("expect err in fetch")
+	}, []TaskOption{FallbackFunc(func(ctx context.Context, err error) error {
+		return errors.New("expect err in fallback")
+	})})
+	err := ds.Run()
+	checkNotNil(t, err)
+	checkEqual(t, "expect err in fetch\ndag: fallback of task:fetch err:expect err in fallback", err.Error())
+	tr, _ := ds.Report().Task("fetch")
+	checkEqual(t, TaskFailed, tr.Status)
+
+	err = NewScheduler[any]().SubmitFuncWithOps("T", func(ctx context.Context, _ any) error { return nil },
+		[]TaskOption{Fallback(func(ctx context.Context, _ int, err error) error { return nil })})
+	checkEqual(t, true, errors.Is(err, ErrInvalidOption))
+}
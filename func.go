@@ -2,6 +2,10 @@ package dagRun
 
 import (
 	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
 )
 
 type nopeCtx struct{}
@@ -23,6 +27,41 @@ func (d *FuncScheduler) WithInjectorFactory(injectFac InjectorFactory[nopeCtx])
 	return d
 }
 
+// WithName set name of scheduler
+func (d *FuncScheduler) WithName(name string) *FuncScheduler {
+	d.scd.WithName(name)
+	return d
+}
+
+// Name return name of scheduler
+func (d *FuncScheduler) Name() string {
+	return d.scd.Name()
+}
+
+// WithLogger set logger of scheduler
+func (d *FuncScheduler) WithLogger(logger *slog.Logger) *FuncScheduler {
+	d.scd.WithLogger(logger)
+	return d
+}
+
+// WithListener add listeners of lifecycle events
+func (d *FuncScheduler) WithListener(listeners ...Listener) *FuncScheduler {
+	d.scd.WithListener(listeners...)
+	return d
+}
+
+// WithGroupConcurrency limit the max number of tasks of group running at the same time
+func (d *FuncScheduler) WithGroupConcurrency(group string, n int) *FuncScheduler {
+	d.scd.WithGroupConcurrency(group, n)
+	return d
+}
+
+// WithGroupTimeout set the default timeout of each task in group
+func (d *FuncScheduler) WithGroupTimeout(group string, timeout time.Duration) *FuncScheduler {
+	d.scd.WithGroupTimeout(group, timeout)
+	return d
+}
+
 // Submit func task to scheduler
 func (d *FuncScheduler) Submit(name string, f func() error, deps ...string) *FuncScheduler {
 	_ = d.scd.SubmitFunc(name, func(ctx context.Context, t nopeCtx) error {
@@ -65,6 +104,38 @@ func (d *FuncScheduler) Run() error {
 	return d.scd.Run(context.Background(), nopeCtx{})
 }
 
+// Report return the report of tasks
+func (d *FuncScheduler) Report() *RunReport {
+	return d.scd.Report()
+}
+
+// WithClock set clock of scheduler, SystemClock by default
+func (d *FuncScheduler) WithClock(clock Clock) *FuncScheduler {
+	d.scd.WithClock(clock)
+	return d
+}
+
+// Plan simulates Run without executing any task
+func (d *FuncScheduler) Plan(ops ...PlanOption) (*Plan, error) {
+	return d.scd.Plan(ops...)
+}
+
+// WithStateStore set store to save checkpoint of each succeeded task
+func (d *FuncScheduler) WithStateStore(store StateStore) *FuncScheduler {
+	d.scd.WithStateStore(store)
+	return d
+}
+
+// Resume run tasks with the id of an interrupted run, tasks succeeded in that run are not executed again
+func (d *FuncScheduler) Resume(runID string) error {
+	return d.scd.Resume(context.Background(), runID, nopeCtx{})
+}
+
+// RunID return id of the run
+func (d *FuncScheduler) RunID() string {
+	return d.scd.RunID()
+}
+
 // Dot dump dag in dot language
 func (d *FuncScheduler) Dot(ops ...DotOption) string {
 	return d.scd.Dot(ops...)
@@ -75,6 +146,26 @@ func (d *FuncScheduler) DOTOnlineURL(ops ...DotOption) string {
 	return d.scd.DOTOnlineURL(ops...)
 }
 
+// Mermaid dump dag in mermaid flowchart
+func (d *FuncScheduler) Mermaid() string {
+	return d.scd.Mermaid()
+}
+
+// PlantUML dump dag in plantUML
+func (d *FuncScheduler) PlantUML() string {
+	return d.scd.PlantUML()
+}
+
+// JSON dump dag in json
+func (d *FuncScheduler) JSON() ([]byte, error) {
+	return d.scd.JSON()
+}
+
+// WriteHTML write a self-contained html page which draws the dag
+func (d *FuncScheduler) WriteHTML(w io.Writer, ops ...HTMLOption) error {
+	return d.scd.WriteHTML(w, ops...)
+}
+
 type funcTaskImpl[T any] struct {
 	name    string
 	deps    []string
@@ -103,7 +194,11 @@ type branchFuncTaskImpl[T any] struct {
 	deps        []string
 	f           func(context.Context, T) (bool, error)
 	options     []TaskOption
-	validBranch bool
+	validBranch atomic.Bool
+}
+
+func (b *branchFuncTaskImpl[T]) Options() []TaskOption {
+	return b.options
 }
 
 func (b *branchFuncTaskImpl[T]) Name() string {
@@ -119,10 +214,11 @@ func (b *branchFuncTaskImpl[T]) Execute(ctx context.Context, t T) error {
 	if err != nil {
 		return err
 	}
-	b.validBranch = valid
+	b.validBranch.Store(valid)
 	return nil
 }
 
-func (b *branchFuncTaskImpl[T]) ValidBranch(context.Context) (valid bool) {
-	return b.validBranch
+// ValidBranch implements Conditioned, so next tasks are skipped when f returns false
+func (b *branchFuncTaskImpl[T]) ValidBranch(context.Context, T) (valid bool) {
+	return b.validBranch.Load()
 }
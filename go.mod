@@ -1,3 +1,3 @@
 module github.com/ycl2018/dag-run
 
-go 1.19
+go 1.21
@@ -108,6 +108,16 @@ func WithNodeAttr(nodeName string, attrs ...string) DotOption {
 	}
 }
 
+// WithCluster draw nodes in a subgraph cluster with label
+func WithCluster(label string, nodeNames ...string) DotOption {
+	return func(dc *dotContext) {
+		if dc.Clusters == nil {
+			dc.Clusters = map[string][]string{}
+		}
+		dc.Clusters[label] = append(dc.Clusters[label], nodeNames...)
+	}
+}
+
 func (g *Graph) DOT(ops ...DotOption) string {
 	var dc = dotContext{}
 	ops = append(ops, WithNodeAttr(StartNodeName, `color="green"`, `shape=doublecircle`))
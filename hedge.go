@@ -0,0 +1,92 @@
+package dagRun
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Hedge start a hedged execution of each attempt in parallel if it hasn't finished after delay
+// measured on clock of scheduler, eg: for tail latency of read tasks. the first succeeded one wins
+// and the other is canceled by ctx, the attempt fails only if both failed.
+// with RateLimit, the hedged execution is not started if no token is available at that time.
+// the task must be safe to execute concurrently with the same runCtx, and TaskInfo.Hedge tells the hedged one.
+// times of hedged executions and wins of them are in TaskReport and TaskEvent
+func Hedge(delay time.Duration) TaskOption {
+	return func(o *option) {
+		o.hedge = delay
+	}
+}
+
+// hedgeResult is result of an execution of attempt
+type hedgeResult struct {
+	hedge bool
+	err   error
+}
+
+// executeAttempt execute an attempt of task, with a hedged execution if set
+func (n *node[T]) executeAttempt(ctx context.Context, task Task[T], t T, info TaskInfo, attempt int, delay time.Duration) error {
+	if delay <= 0 {
+		return task.Execute(n.attemptContext(ctx, info, attempt), t)
+	}
+	// cancel the loser
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan hedgeResult, 2)
+	var execute = func(hedge bool) {
+		info := info
+		info.Hedge = hedge
+		go func() {
+			var err error
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("dag: task:%s panic:%v \n%s", task.Name(), r, debug.Stack())
+				}
+				results <- hedgeResult{hedge: hedge, err: err}
+			}()
+			err = task.Execute(n.attemptContext(ctx, info, attempt), t)
+		}()
+	}
+	execute(false)
+	timer := n.ds.clock.NewTimer(delay)
+	defer timer.Stop()
+	var (
+		hedgeC  = timer.C()
+		pending = 1
+		err     error
+	)
+	for pending > 0 {
+		select {
+		case <-hedgeC:
+			hedgeC = nil
+			// hedged execution takes a token of rate limit too, not hedged if no token now
+			if !n.tryToken() {
+				continue
+			}
+			n.mu.Lock()
+			n.report.Hedged++
+			n.mu.Unlock()
+			execute(true)
+			pending++
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if r.hedge {
+					n.mu.Lock()
+					n.report.HedgeWins++
+					n.mu.Unlock()
+				}
+				return nil
+			}
+			if err == nil {
+				err = r.err
+			}
+			// failed before delay, no need to hedge
+			if hedgeC != nil {
+				return err
+			}
+		}
+	}
+	return err
+}
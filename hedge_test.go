@@ -0,0 +1,107 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedge(t *testing.T) {
+	var canceled = make(chan error, 1)
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitFuncWithOps("read", func(ctx context.Context, _ any) error {
+		if info, _ := InfoFromContext(ctx); info.Hedge {
+			return nil
+		}
+		// primary is slow
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return ctx.Err()
+	}, []TaskOption{Hedge(10 * time.Millisecond), Timeout(time.Second)}))
+	checkNil(t, ds.Run(context.Background(), nil))
+	checkEqual(t, context.Canceled, <-canceled)
+	r, _ := ds.Report().Task("read")
+	checkEqual(t, TaskSuccess, r.Status)
+	checkEqual(t, 1, r.Attempts)
+	checkEqual(t, 1, r.Hedged)
+	checkEqual(t, 1, r.HedgeWins)
+
+	// finished before delay
+	var calls atomic.Int32
+	ds = NewScheduler[any]()
+	checkNil(t, ds.SubmitFuncWithOps("read", func(ctx context.Context, _ any) error {
+		calls.Add(1)
+		return nil
+	}, []TaskOption{Hedge(time.Second)}))
+	checkNil(t, ds.Run(context.Background(), nil))
+	r, _ = ds.Report().Task("read")
+	checkEqual(t, int32(1), calls.Load())
+	checkEqual(t, 0, r.Hedged)
+}
+
+func TestHedgeErr(t *testing.T) {
+	// both executions of each attempt failed
+	var calls atomic.Int32
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitFuncWithOps("read", func(ctx context.Context, _ any) error {
+		calls.Add(1)
+		if info, _ := InfoFromContext(ctx); info.Hedge {
+			return errors.New("expect err in hedge")
+		}
+		time.Sleep(20 * time.Millisecond)
+		return errors.New("expect err in read")
+	}, []TaskOption{Hedge(5 * time.Millisecond), Retry(2)}))
+	err := ds.Run(context.Background(), nil)
+	checkEqual(t, "expect err in hedge", err.Error())
+	r, _ := ds.Report().Task("read")
+	checkEqual(t, int32(4), calls.Load())
+	checkEqual(t, 2, r.Attempts)
+	checkEqual(t, 2, r.Hedged)
+	checkEqual(t, 0, r.HedgeWins)
+
+	// failed before delay, not hedged
+	calls.Store(0)
+	ds = NewScheduler[any]()
+	checkNil(t, ds.SubmitFuncWithOps("read", func(ctx context.Context, _ any) error {
+		calls.Add(1)
+		panic("expect panic")
+	}, []TaskOption{Hedge(time.Second)}))
+	checkNotNil(t, ds.Run(context.Background(), nil))
+	r, _ = ds.Report().Task("read")
+	checkEqual(t, int32(1), calls.Load())
+	checkEqual(t, 0, r.Hedged)
+}
+
+func TestHedgeRateLimit(t *testing.T) {
+	const name = "TestHedgeRateLimit"
+	defer limiters.Delete(name)
+	var calls atomic.Int32
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitFuncWithOps(name, func(ctx context.Context, _ any) error {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}, []TaskOption{RateLimit(1, time.Hour), Hedge(5 * time.Millisecond)}))
+	checkNil(t, ds.Run(context.Background(), nil))
+	r, _ := ds.Report().Task(name)
+	checkEqual(t, int32(1), calls.Load())
+	checkEqual(t, 0, r.Hedged)
+
+	// hedged with tokens available
+	calls.Store(0)
+	ds = NewScheduler[any]()
+	checkNil(t, ds.SubmitFuncWithOps(name+"2", func(ctx context.Context, _ any) error {
+		calls.Add(1)
+		if info, _ := InfoFromContext(ctx); !info.Hedge {
+			<-ctx.Done()
+		}
+		return nil
+	}, []TaskOption{RateLimit(2, time.Hour), Hedge(5 * time.Millisecond)}))
+	defer limiters.Delete(name + "2")
+	checkNil(t, ds.Run(context.Background(), nil))
+	r, _ = ds.Report().Task(name + "2")
+	checkEqual(t, int32(2), calls.Load())
+	checkEqual(t, 1, r.HedgeWins)
+}
@@ -0,0 +1,223 @@
+package dagRun
+
+import (
+	"html/template"
+	"io"
+	"os"
+	"time"
+)
+
+// HTMLOption config the html page of WriteHTML
+type HTMLOption func(hc *htmlContext)
+
+type htmlContext struct {
+	Title  string
+	Graph  GraphJSON
+	Report *reportJSON
+}
+
+// reportJSON is the json model of run report, times of tasks are milliseconds from run start
+type reportJSON struct {
+	Start    time.Time        `json:"start"`
+	Duration float64          `json:"duration"`
+	Err      string           `json:"err,omitempty"`
+	Tasks    []taskReportJSON `json:"tasks"`
+}
+
+type taskReportJSON struct {
+	Name       string        `json:"name"`
+	Group      string        `json:"group,omitempty"`
+	Status     string        `json:"status"`
+	Attempts   int           `json:"attempts,omitempty"`
+	Start      float64       `json:"start"`
+	End        float64       `json:"end"`
+	Err        string        `json:"err,omitempty"`
+	Throttled  float64       `json:"throttled,omitempty"`
+	Hedged     int           `json:"hedged,omitempty"`
+	HedgeWins  int           `json:"hedgeWins,omitempty"`
+	Sub        *reportJSON   `json:"sub,omitempty"`
+	Iterations []*reportJSON `json:"iterations,omitempty"`
+}
+
+func newReportJSON(report *RunReport) *reportJSON {
+	var ms = func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	rj := &reportJSON{Start: report.Start, Duration: ms(report.Duration()), Tasks: []taskReportJSON{}}
+	if report.Err != nil {
+		rj.Err = report.Err.Error()
+	}
+	for _, t := range report.Tasks {
+		tj := taskReportJSON{Name: t.Name, Group: t.Group, Status: string(t.Status), Attempts: t.Attempts}
+		if !t.Start.IsZero() {
+			tj.Start = ms(t.Start.Sub(report.Start))
+		}
+		if !t.End.IsZero() {
+			tj.End = ms(t.End.Sub(report.Start))
+		}
+		if t.Err != nil {
+			tj.Err = t.Err.Error()
+		}
+		tj.Throttled = ms(t.Throttled)
+		tj.Hedged, tj.HedgeWins = t.Hedged, t.HedgeWins
+		if t.Sub != nil {
+			tj.Sub = newReportJSON(t.Sub)
+		}
+		for _, it := range t.Iterations {
+			tj.Iterations = append(tj.Iterations, newReportJSON(it))
+		}
+		rj.Tasks = append(rj.Tasks, tj)
+	}
+	return rj
+}
+
+// WithHTMLTitle set title of the html page
+func WithHTMLTitle(title string) HTMLOption {
+	return func(hc *htmlContext) {
+		hc.Title = title
+	}
+}
+
+// WithHTMLReport draw the status of tasks and a timeline of report in the html page
+func WithHTMLReport(report *RunReport) HTMLOption {
+	return func(hc *htmlContext) {
+		if report == nil {
+			hc.Report = nil
+			return
+		}
+		hc.Report = newReportJSON(report)
+	}
+}
+
+// WriteHTML write a self-contained html page which draws the dag without any network access
+func (d *Scheduler[T]) WriteHTML(w io.Writer, ops ...HTMLOption) error {
+	hc := htmlContext{Title: "dag-run", Graph: d.view().json()}
+	for _, op := range ops {
+		op(&hc)
+	}
+	return htmlTemplate.Execute(w, hc)
+}
+
+// SaveHTML write the html page of WriteHTML to file
+func (d *Scheduler[T]) SaveHTML(filename string, ops ...HTMLOption) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := d.WriteHTML(f, ops...); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+var htmlTemplate = template.Must(template.New("dag").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 16px; }
+svg text { font-size: 12px; }
+.edge { fill: none; stroke: #888; }
+.node { fill: #fff; stroke: #333; }
+.cluster { fill: #f5f5f5; stroke: #bbb; stroke-dasharray: 4 2; }
+.success { fill: #c8e6c9; }
+.failed { fill: #ffcdd2; }
+.skipped { fill: #eeeeee; }
+.canceled { fill: #ffe0b2; }
+.running { fill: #bbdefb; }
+.fallback { fill: #fff9c4; }
+#err { color: #c62828; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h2>{{.Title}}</h2>
+<svg id="graph"></svg>
+<div id="err"></div>
+<svg id="timeline"></svg>
+<script>
+const graph = {{.Graph}};
+const report = {{.Report}};
+const NS = "http://www.w3.org/2000/svg";
+function el(parent, tag, attrs, text) {
+  const e = document.createElementNS(NS, tag);
+  for (const k in attrs) e.setAttribute(k, attrs[k]);
+  if (text !== undefined) e.textContent = text;
+  parent.appendChild(e);
+  return e;
+}
+const status = {};
+if (report) {
+  for (const t of report.tasks) status[t.name] = t;
+  if (report.err) document.getElementById("err").textContent = report.err;
+}
+// layer nodes by the longest path from root nodes
+const deps = {}, level = {};
+for (const n of graph.nodes) deps[n.name] = [];
+for (const e of graph.edges) deps[e.to].push(e.from);
+function depth(name) {
+  if (level[name] === undefined) {
+    level[name] = 0;
+    for (const d of deps[name]) level[name] = Math.max(level[name], depth(d) + 1);
+  }
+  return level[name];
+}
+const columns = [];
+for (const n of graph.nodes) {
+  const l = depth(n.name);
+  (columns[l] = columns[l] || []).push(n);
+}
+const W = 120, H = 36, GX = 60, GY = 24, pos = {};
+let maxRows = 0;
+columns.forEach((col, x) => {
+  col.sort((a, b) => (a.group || "").localeCompare(b.group || "") || a.name.localeCompare(b.name));
+  col.forEach((n, y) => pos[n.name] = {x: 20 + x * (W + GX), y: 20 + y * (H + GY)});
+  maxRows = Math.max(maxRows, col.length);
+});
+const g = document.getElementById("graph");
+g.setAttribute("width", 40 + columns.length * (W + GX));
+g.setAttribute("height", 40 + maxRows * (H + GY));
+const groups = {};
+for (const n of graph.nodes) if (n.group) (groups[n.group] = groups[n.group] || []).push(pos[n.name]);
+for (const name in groups) {
+  const ps = groups[name];
+  const x1 = Math.min(...ps.map(p => p.x)) - 8, y1 = Math.min(...ps.map(p => p.y)) - 16;
+  const x2 = Math.max(...ps.map(p => p.x)) + W + 8, y2 = Math.max(...ps.map(p => p.y)) + H + 8;
+  el(g, "rect", {class: "cluster", x: x1, y: y1, width: x2 - x1, height: y2 - y1, rx: 6});
+  el(g, "text", {x: x1 + 4, y: y1 + 11, fill: "#666"}, name);
+}
+for (const e of graph.edges) {
+  const a = pos[e.from], b = pos[e.to];
+  const x1 = a.x + W, y1 = a.y + H / 2, x2 = b.x, y2 = b.y + H / 2;
+  el(g, "path", {class: "edge", d: "M" + x1 + "," + y1 + " C" + (x1 + GX / 2) + "," + y1 + " " + (x2 - GX / 2) + "," + y2 + " " + x2 + "," + y2});
+}
+for (const n of graph.nodes) {
+  const p = pos[n.name], s = status[n.name], cls = "node" + (s ? " " + s.status : "");
+  if (n.branch) {
+    el(g, "polygon", {class: cls, points: [p.x, p.y + H / 2, p.x + W / 2, p.y, p.x + W, p.y + H / 2, p.x + W / 2, p.y + H].join(",")});
+  } else {
+    el(g, "rect", {class: cls, x: p.x, y: p.y, width: W, height: H, rx: 4});
+  }
+  el(g, "text", {x: p.x + W / 2, y: p.y + H / 2 + 4, "text-anchor": "middle"}, n.name);
+  el(g.lastChild, "title", {}, n.name + (s ? " " + s.status + (s.err ? ": " + s.err : "") : ""));
+}
+// gantt bars of report
+if (report) {
+  const tl = document.getElementById("timeline");
+  const tasks = report.tasks.filter(t => t.end > 0).sort((a, b) => a.start - b.start || a.name.localeCompare(b.name));
+  const LW = 140, BW = 600, RH = 22, total = report.duration || 1;
+  tl.setAttribute("width", LW + BW + 80);
+  tl.setAttribute("height", 30 + tasks.length * RH);
+  el(tl, "text", {x: LW, y: 14}, "0ms");
+  el(tl, "text", {x: LW + BW, y: 14, "text-anchor": "end"}, total + "ms");
+  tasks.forEach((t, i) => {
+    const y = 22 + i * RH;
+    el(tl, "text", {x: 0, y: y + 14}, t.name);
+    el(tl, "rect", {class: "node " + t.status, x: LW + t.start / total * BW, y: y, height: RH - 6,
+      width: Math.max(1, (t.end - t.start) / total * BW)});
+    el(tl, "text", {x: LW + t.end / total * BW + 4, y: y + 14}, (Math.round((t.end - t.start) * 1000) / 1000) + "ms");
+  });
+}
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,35 @@
+package dagRun
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	scd := exportScheduler()
+	checkNil(t, scd.Run())
+	var buf bytes.Buffer
+	checkNil(t, scd.WriteHTML(&buf, WithHTMLTitle("test <dag>"), WithHTMLReport(scd.Report())))
+	page := buf.String()
+	checkEqual(t, true, strings.Contains(page, "<title>test &lt;dag&gt;</title>"))
+	checkEqual(t, true, strings.Contains(page, `const graph = {"nodes":[{"name":"A","group":"fetch"`))
+	checkEqual(t, true, strings.Contains(page, `"status":"success"`))
+	// self-contained: no url except the namespace of svg
+	for _, url := range regexp.MustCompile(`https?://[^\s"'<>]*`).FindAllString(page, -1) {
+		checkEqual(t, "http://www.w3.org/2000/svg", url)
+	}
+	checkEqual(t, false, strings.Contains(page, "<script src"))
+}
+
+func TestSaveHTML(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dag.html")
+	checkNil(t, exportScheduler().scd.SaveHTML(filename))
+	data, err := os.ReadFile(filename)
+	checkNil(t, err)
+	checkEqual(t, true, strings.Contains(string(data), `"name":"D"`))
+	checkEqual(t, false, strings.Contains(string(data), `"status"`))
+}
@@ -0,0 +1,75 @@
+package dagRun
+
+import (
+	"context"
+	"time"
+)
+
+// sources of task deadline
+const (
+	DeadlineTask    = "task"    // Timeout option of task
+	DeadlineGroup   = "group"   // WithGroupTimeout of scheduler
+	DeadlineContext = "context" // deadline of ctx passed to Run
+)
+
+// TaskInfo is the execution metadata of task, tasks get it by InfoFromContext
+type TaskInfo struct {
+	RunID     string
+	Scheduler string
+	Task      string
+	Group     string
+	// Attempt is the current attempt, from 1
+	Attempt int
+	// Hedge is true in the hedged execution of attempt
+	Hedge bool
+	// Start is the start time of the first attempt
+	Start time.Time
+	// Deadline of task, zero if task has no deadline, DeadlineSource tells where it comes from
+	Deadline       time.Time
+	DeadlineSource string
+	// Parents is the status of dependencies
+	Parents map[string]TaskStatus
+}
+
+type infoKey struct{}
+
+// InfoFromContext return the TaskInfo in ctx passed to Task.Execute
+func InfoFromContext(ctx context.Context) (TaskInfo, bool) {
+	info, ok := ctx.Value(infoKey{}).(TaskInfo)
+	return info, ok
+}
+
+func (n *node[T]) info(ctx context.Context, op option) TaskInfo {
+	r := n.getReport()
+	info := TaskInfo{
+		RunID:     n.ds.runID,
+		Scheduler: n.ds.name,
+		Task:      r.Name,
+		Group:     r.Group,
+		Start:     n.ds.clock.Now(),
+		Parents:   make(map[string]TaskStatus, len(n.task.Dependencies())),
+	}
+	if op.timeout > 0 {
+		info.Deadline = info.Start.Add(op.timeout)
+		info.DeadlineSource = n.deadlineSource
+	}
+	if deadline, ok := ctx.Deadline(); ok && (info.Deadline.IsZero() || deadline.Before(info.Deadline)) {
+		info.Deadline = deadline
+		info.DeadlineSource = DeadlineContext
+	}
+	for _, name := range n.task.Dependencies() {
+		if pre, ok := n.ds.node(name); ok {
+			info.Parents[name] = pre.getReport().Status
+		}
+	}
+	return info
+}
+
+// attemptContext return ctx passed to Task.Execute for attempt
+func (n *node[T]) attemptContext(ctx context.Context, info TaskInfo, attempt int) context.Context {
+	info.Attempt = attempt
+	ctx = context.WithValue(ctx, infoKey{}, info)
+	ctx = context.WithValue(ctx, spawnKey{}, n)
+	ctx = n.ds.listeners.AttemptContext(ctx, n.event(attempt, nil))
+	return withLogger(ctx, n.ds.taskLogger(info.Task, attempt))
+}
@@ -0,0 +1,106 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInfoFromContext(t *testing.T) {
+	var infos sync.Map
+	var record = func(ctx context.Context) {
+		info, ok := InfoFromContext(ctx)
+		if ok {
+			infos.Store(info.Task+":"+string(rune('0'+info.Attempt)), info)
+		}
+	}
+	var tries int
+	ds := NewScheduler[any]().WithName("info").WithGroupTimeout("g", time.Second)
+	checkNil(t, ds.SubmitFuncWithOps("T1", func(ctx context.Context, _ any) error {
+		record(ctx)
+		tries++
+		if tries < 2 {
+			return errors.New("expect err in T1")
+		}
+		return nil
+	}, []TaskOption{Retry(2), Timeout(time.Minute)}))
+	checkNil(t, ds.SubmitBranchFunc("B1", func(ctx context.Context, _ any) (bool, error) {
+		return false, nil
+	}))
+	checkNil(t, ds.SubmitFunc("T2", func(ctx context.Context, _ any) error { return nil }, "B1"))
+	checkNil(t, ds.SubmitFuncWithOps("T3", func(ctx context.Context, _ any) error {
+		record(ctx)
+		return nil
+	}, []TaskOption{Group("g")}, "T1", "T2"))
+	checkNil(t, ds.SubmitFunc("T4", func(ctx context.Context, _ any) error {
+		record(ctx)
+		return nil
+	}, "T3"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	checkNil(t, ds.Run(ctx, nil))
+
+	value, ok := infos.Load("T1:1")
+	checkEqual(t, true, ok)
+	info1 := value.(TaskInfo)
+	checkEqual(t, ds.RunID(), info1.RunID)
+	checkEqual(t, "info", info1.Scheduler)
+	checkEqual(t, DeadlineTask, info1.DeadlineSource)
+	checkEqual(t, info1.Start.Add(time.Minute), info1.Deadline)
+	checkEqual(t, 0, len(info1.Parents))
+	value, _ = infos.Load("T1:2")
+	info2 := value.(TaskInfo)
+	checkEqual(t, 2, info2.Attempt)
+	checkEqual(t, info1.Start, info2.Start)
+
+	value, _ = infos.Load("T3:1")
+	info3 := value.(TaskInfo)
+	checkEqual(t, "g", info3.Group)
+	checkEqual(t, DeadlineGroup, info3.DeadlineSource)
+	checkEqual(t, TaskSuccess, info3.Parents["T1"])
+	checkEqual(t, TaskSkipped, info3.Parents["T2"])
+
+	value, _ = infos.Load("T4:1")
+	checkEqual(t, DeadlineContext, value.(TaskInfo).DeadlineSource)
+
+	_, ok = InfoFromContext(context.Background())
+	checkEqual(t, false, ok)
+}
+
+func TestTimeoutCancelContext(t *testing.T) {
+	canceled := make(chan error, 1)
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitFuncWithOps("T1", func(ctx context.Context, _ any) error {
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return ctx.Err()
+	}, []TaskOption{Timeout(10 * time.Millisecond)}))
+	err := ds.Run(context.Background(), nil)
+	checkEqual(t, true, errors.Is(err, ErrTaskTimeout))
+	checkNotNil(t, <-canceled)
+}
+
+func TestTimeoutStopsRetry(t *testing.T) {
+	var calls atomic.Int32
+	rl := &recordListener{}
+	ds := NewScheduler[any]().WithListener(rl)
+	checkNil(t, ds.SubmitFuncWithOps("T1", func(ctx context.Context, _ any) error {
+		calls.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	}, []TaskOption{Timeout(10 * time.Millisecond), Retry(5)}))
+	err := ds.Run(context.Background(), nil)
+	checkEqual(t, true, errors.Is(err, ErrTaskTimeout))
+	// attempts not retried after timeout
+	time.Sleep(30 * time.Millisecond)
+	checkEqual(t, int32(1), calls.Load())
+	tr, _ := ds.Report().Task("T1")
+	checkEqual(t, 1, tr.Attempts)
+	for _, e := range rl.sorted() {
+		checkEqual(t, false, strings.HasPrefix(e, "retry:"))
+	}
+}
@@ -2,11 +2,14 @@ package dagRun
 
 import "context"
 
+// Injector do something before and after task, After is called with the error of Pre
+// when Pre fails, and the task is not executed then. see also Middleware
 type Injector[T any] struct {
 	Pre   func(ctx context.Context, runCtx T) error
 	After func(ctx context.Context, runCtx T, err error) error
 }
 
+// InjectorFactory build an Injector for each task
 type InjectorFactory[T any] interface {
 	Inject(ctx context.Context, task Task[T]) Injector[T]
 }
@@ -0,0 +1,140 @@
+package dagRun
+
+import (
+	"context"
+	"time"
+)
+
+// Listener listens lifecycle events of scheduler, it's called in the goroutines of tasks,
+// so it should be safe for concurrent use. embed NopListener to implement only part of it
+type Listener interface {
+	// OnRunStart is called when Run start
+	OnRunStart(ctx context.Context, e RunEvent)
+	// OnTaskReady is called when all dependencies of task finished, before waiting for group limit
+	OnTaskReady(ctx context.Context, e TaskEvent)
+	// OnTaskStart is called before the first attempt of task executes
+	OnTaskStart(ctx context.Context, e TaskEvent)
+	// OnTaskRetry is called before a retry attempt executes, Err is the error of the last attempt
+	OnTaskRetry(ctx context.Context, e TaskEvent)
+	// OnTaskSkip is called when task not executed, Err is ErrSkipped for branch skip or ErrCanceled for cancel
+	OnTaskSkip(ctx context.Context, e TaskEvent)
+	// OnTaskEnd is called when task finished, Err is the final error of task
+	OnTaskEnd(ctx context.Context, e TaskEvent)
+	// OnRunEnd is called when Run finished
+	OnRunEnd(ctx context.Context, e RunEvent)
+}
+
+// RunEvent is the event of run lifecycle
+type RunEvent struct {
+	RunID     string
+	Scheduler string
+	Start     time.Time
+	End       time.Time
+	Err       error
+}
+
+// Duration return running time of run, zero if run not finished
+func (e RunEvent) Duration() time.Duration {
+	if e.Start.IsZero() || e.End.IsZero() {
+		return 0
+	}
+	return e.End.Sub(e.Start)
+}
+
+// TaskEvent is the event of task lifecycle
+type TaskEvent struct {
+	RunID     string
+	Scheduler string
+	Task      string
+	Group     string
+	Attempt   int
+	Ready     time.Time
+	Start     time.Time
+	End       time.Time
+	Err       error
+	// Throttled is the time waited for tokens of rate limit, which is not counted in Duration
+	Throttled time.Duration
+	// Hedged is times of hedged executions started, and HedgeWins is times they won
+	Hedged    int
+	HedgeWins int
+}
+
+// Duration return running time of task except Throttled, zero if task not finished
+func (e TaskEvent) Duration() time.Duration {
+	if e.Start.IsZero() || e.End.IsZero() {
+		return 0
+	}
+	return e.End.Sub(e.Start) - e.Throttled
+}
+
+// AttemptContexter is implemented by listeners which propagate values through ctx passed to
+// Task.Execute, eg: span of attempt started in OnTaskStart or OnTaskRetry. it's called for each attempt
+type AttemptContexter interface {
+	AttemptContext(ctx context.Context, e TaskEvent) context.Context
+}
+
+// NopListener implements Listener doing nothing
+type NopListener struct{}
+
+func (NopListener) OnRunStart(context.Context, RunEvent)   {}
+func (NopListener) OnTaskReady(context.Context, TaskEvent) {}
+func (NopListener) OnTaskStart(context.Context, TaskEvent) {}
+func (NopListener) OnTaskRetry(context.Context, TaskEvent) {}
+func (NopListener) OnTaskSkip(context.Context, TaskEvent)  {}
+func (NopListener) OnTaskEnd(context.Context, TaskEvent)   {}
+func (NopListener) OnRunEnd(context.Context, RunEvent)     {}
+
+// Listeners composes listeners, events are sent to each of them in order
+type Listeners []Listener
+
+func (ls Listeners) OnRunStart(ctx context.Context, e RunEvent) {
+	for _, l := range ls {
+		l.OnRunStart(ctx, e)
+	}
+}
+
+func (ls Listeners) OnTaskReady(ctx context.Context, e TaskEvent) {
+	for _, l := range ls {
+		l.OnTaskReady(ctx, e)
+	}
+}
+
+func (ls Listeners) OnTaskStart(ctx context.Context, e TaskEvent) {
+	for _, l := range ls {
+		l.OnTaskStart(ctx, e)
+	}
+}
+
+func (ls Listeners) OnTaskRetry(ctx context.Context, e TaskEvent) {
+	for _, l := range ls {
+		l.OnTaskRetry(ctx, e)
+	}
+}
+
+func (ls Listeners) OnTaskSkip(ctx context.Context, e TaskEvent) {
+	for _, l := range ls {
+		l.OnTaskSkip(ctx, e)
+	}
+}
+
+func (ls Listeners) OnTaskEnd(ctx context.Context, e TaskEvent) {
+	for _, l := range ls {
+		l.OnTaskEnd(ctx, e)
+	}
+}
+
+func (ls Listeners) OnRunEnd(ctx context.Context, e RunEvent) {
+	for _, l := range ls {
+		l.OnRunEnd(ctx, e)
+	}
+}
+
+// AttemptContext implements AttemptContexter by listeners implementing it in order
+func (ls Listeners) AttemptContext(ctx context.Context, e TaskEvent) context.Context {
+	for _, l := range ls {
+		if ac, ok := l.(AttemptContexter); ok {
+			ctx = ac.AttemptContext(ctx, e)
+		}
+	}
+	return ctx
+}
@@ -0,0 +1,103 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordListener struct {
+	NopListener
+	lock   sync.Mutex
+	events []string
+}
+
+func (r *recordListener) record(format string, args ...any) {
+	r.lock.Lock()
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+	r.lock.Unlock()
+}
+
+func (r *recordListener) OnRunStart(_ context.Context, e RunEvent) {
+	r.record("run start:%s", e.Scheduler)
+}
+
+func (r *recordListener) OnTaskReady(_ context.Context, e TaskEvent) {
+	r.record("ready:%s", e.Task)
+}
+
+func (r *recordListener) OnTaskStart(_ context.Context, e TaskEvent) {
+	r.record("start:%s attempt:%d", e.Task, e.Attempt)
+}
+
+func (r *recordListener) OnTaskRetry(_ context.Context, e TaskEvent) {
+	r.record("retry:%s attempt:%d err:%v", e.Task, e.Attempt, e.Err)
+}
+
+func (r *recordListener) OnTaskSkip(_ context.Context, e TaskEvent) {
+	r.record("skip:%s err:%v", e.Task, e.Err)
+}
+
+func (r *recordListener) OnTaskEnd(_ context.Context, e TaskEvent) {
+	r.record("end:%s attempts:%d err:%v", e.Task, e.Attempt, e.Err)
+}
+
+func (r *recordListener) OnRunEnd(_ context.Context, e RunEvent) {
+	r.record("run end:%s err:%v", e.Scheduler, e.Err)
+}
+
+func (r *recordListener) sorted() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	events := append([]string(nil), r.events...)
+	sort.Strings(events)
+	return events
+}
+
+func TestListener(t *testing.T) {
+	var tries int
+	rl := &recordListener{}
+	err := NewFuncScheduler().WithName("listener").WithListener(NopListener{}, rl).
+		SubmitWithOps("T1", func() error {
+			tries++
+			if tries < 3 {
+				return fmt.Errorf("try %d", tries)
+			}
+			return nil
+		}, []TaskOption{Retry(3)}).
+		SubmitBranch("B1", func() (bool, error) { return false, nil }).
+		Submit("T2", func() error { return nil }, "B1").
+		SubmitWithOps("T3", func() error { time.Sleep(50 * time.Millisecond); return nil },
+			[]TaskOption{Timeout(10 * time.Millisecond)}, "T1").
+		Submit("T4", func() error { return nil }, "T3").
+		Run()
+	checkEqual(t, true, errors.Is(err, ErrTaskTimeout))
+	want := []string{
+		"end:B1 attempts:1 err:<nil>",
+		"end:T1 attempts:3 err:<nil>",
+		"end:T3 attempts:1 err:dag: task:T3 run timeout",
+		"ready:B1",
+		"ready:T1",
+		"ready:T3",
+		"retry:T1 attempt:2 err:try 1",
+		"retry:T1 attempt:3 err:try 2",
+		"run end:listener err:dag: task:T3 run timeout",
+		"run start:listener",
+		"skip:T2 err:dagRun: task skipped by branch",
+		"skip:T4 err:dagRun: task canceled",
+		"start:B1 attempt:1",
+		"start:T1 attempt:1",
+		"start:T3 attempt:1",
+	}
+	get := rl.sorted()
+	checkEqual(t, len(want), len(get))
+	for i := range want {
+		if i < len(get) {
+			checkEqual(t, want[i], get[i])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package dagRun
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// keys of log attributes
+const (
+	LogKeyRunID     = "run_id"
+	LogKeyScheduler = "scheduler"
+	LogKeyTask      = "task"
+	LogKeyAttempt   = "attempt"
+	LogKeyDuration  = "duration"
+	LogKeyError     = "error"
+)
+
+// newRunID generate a random id for run
+func newRunID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+type loggerKey struct{}
+
+func withLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFromContext return the task-scoped logger in ctx passed to Task.Execute,
+// which has attributes of run_id, scheduler, task and attempt. slog.Default() is returned if not found
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+func (d *Scheduler[T]) taskLogger(task string, attempt int) *slog.Logger {
+	logger := d.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return logger.With(LogKeyRunID, d.runID, LogKeyScheduler, d.name, LogKeyTask, task, LogKeyAttempt, attempt)
+}
+
+// logListener logs lifecycle events of run and tasks
+type logListener struct {
+	NopListener
+	logger **slog.Logger // logger of scheduler
+}
+
+func (l logListener) log() *slog.Logger {
+	if *l.logger == nil {
+		return slog.Default()
+	}
+	return *l.logger
+}
+
+func (l logListener) OnRunStart(ctx context.Context, e RunEvent) {
+	l.log().InfoContext(ctx, "dag run start", LogKeyRunID, e.RunID, LogKeyScheduler, e.Scheduler)
+}
+
+func (l logListener) OnRunEnd(ctx context.Context, e RunEvent) {
+	attrs := []any{LogKeyRunID, e.RunID, LogKeyScheduler, e.Scheduler, LogKeyDuration, e.Duration()}
+	if e.Err != nil {
+		l.log().ErrorContext(ctx, "dag run end", append(attrs, LogKeyError, e.Err)...)
+		return
+	}
+	l.log().InfoContext(ctx, "dag run end", attrs...)
+}
+
+func (l logListener) OnTaskStart(ctx context.Context, e TaskEvent) {
+	l.log().DebugContext(ctx, "dag task start", taskAttrs(e)...)
+}
+
+func (l logListener) OnTaskRetry(ctx context.Context, e TaskEvent) {
+	l.log().WarnContext(ctx, "dag task retry", append(taskAttrs(e), LogKeyError, e.Err)...)
+}
+
+func (l logListener) OnTaskSkip(ctx context.Context, e TaskEvent) {
+	l.log().InfoContext(ctx, "dag task skip", append(taskAttrs(e), LogKeyError, e.Err)...)
+}
+
+func (l logListener) OnTaskEnd(ctx context.Context, e TaskEvent) {
+	attrs := append(taskAttrs(e), LogKeyDuration, e.Duration())
+	if e.Err != nil {
+		l.log().ErrorContext(ctx, "dag task end", append(attrs, LogKeyError, e.Err)...)
+		return
+	}
+	l.log().InfoContext(ctx, "dag task end", attrs...)
+}
+
+func taskAttrs(e TaskEvent) []any {
+	return []any{LogKeyRunID, e.RunID, LogKeyScheduler, e.Scheduler, LogKeyTask, e.Task, LogKeyAttempt, e.Attempt}
+}
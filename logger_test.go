@@ -0,0 +1,102 @@
+package dagRun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSchedulerLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ds := NewScheduler[any]().WithName("logger").WithLogger(logger)
+	var tries int
+	checkNil(t, ds.SubmitFuncWithOps("T1", func(ctx context.Context, _ any) error {
+		tries++
+		LoggerFromContext(ctx).Info("in task")
+		if tries < 2 {
+			return errors.New("expect err in T1")
+		}
+		return nil
+	}, []TaskOption{Retry(2)}))
+	checkNil(t, ds.Run(context.Background(), nil))
+	runID := ds.RunID()
+	checkEqual(t, 16, len(runID))
+	checkEqual(t, runID, ds.Report().RunID)
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		checkNil(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	wants := []struct {
+		msg     string
+		task    string
+		attempt float64
+	}{
+		{msg: "dag run start"},
+		{msg: "dag task start", task: "T1", attempt: 1},
+		{msg: "in task", task: "T1", attempt: 1},
+		{msg: "dag task retry", task: "T1", attempt: 2},
+		{msg: "in task", task: "T1", attempt: 2},
+		{msg: "dag task end", task: "T1", attempt: 2},
+		{msg: "dag run end"},
+	}
+	checkEqual(t, len(wants), len(records))
+	for i, want := range wants {
+		if i >= len(records) {
+			break
+		}
+		record := records[i]
+		checkEqual[any](t, want.msg, record["msg"])
+		checkEqual[any](t, runID, record[LogKeyRunID])
+		checkEqual[any](t, "logger", record[LogKeyScheduler])
+		if want.task != "" {
+			checkEqual[any](t, want.task, record[LogKeyTask])
+			checkEqual[any](t, want.attempt, record[LogKeyAttempt])
+		}
+	}
+}
+
+func TestSchedulerWithLoggerTwice(t *testing.T) {
+	var first, second bytes.Buffer
+	ds := NewScheduler[any]().
+		WithLogger(nil).
+		WithLogger(slog.New(slog.NewTextHandler(&first, nil))).
+		WithLogger(slog.New(slog.NewTextHandler(&second, nil)))
+	checkNil(t, ds.SubmitFunc("T1", func(ctx context.Context, _ any) error { return nil }))
+	checkNil(t, ds.Run(context.Background(), nil))
+	checkEqual(t, 0, first.Len())
+	checkEqual(t, 1, strings.Count(second.String(), "dag run start"))
+	checkEqual(t, 1, strings.Count(second.String(), "dag task end"))
+
+	// slog.Default() is used with nil logger
+	ds = NewScheduler[any]().WithLogger(nil)
+	checkNil(t, ds.SubmitFunc("T1", func(ctx context.Context, _ any) error { return nil }))
+	checkNil(t, ds.Run(context.Background(), nil))
+}
+
+func TestLoggerFromContextDefault(t *testing.T) {
+	checkEqual(t, slog.Default(), LoggerFromContext(context.Background()))
+}
+
+func TestRegistryLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tm := NewTaskManager[myTask]().WithLogger(logger)
+	tm.Register(myTask{name: "taskA"})
+	_, err := tm.GetAllTaskWithDepsByName([]string{"taskB"})
+	checkNotNil(t, err)
+	checkEqual(t, true, strings.Contains(buf.String(), `msg="dagRun: register" name=taskA`))
+	checkEqual(t, true, strings.Contains(buf.String(), `msg="dagRun: task not registered" task=taskB`))
+	defer func() {
+		checkEqual[any](t, "duplicate register name:taskA", recover())
+		checkEqual(t, true, strings.Contains(buf.String(), `msg="dagRun: duplicate register" name=taskA`))
+	}()
+	tm.Register(myTask{name: "taskA"})
+}
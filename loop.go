@@ -0,0 +1,146 @@
+package dagRun
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LoopSpec describes a loop node: the body runs again and again while While is true,
+// up to MaxIterations times or until Deadline, at least one of them must be set
+type LoopSpec[T any] struct {
+	// Body build a new scheduler for each iteration, as a scheduler can only run once
+	Body func() *Scheduler[T]
+	// While is checked before each iteration, loop ends when it returns false
+	While func(ctx context.Context, t T) bool
+	// MaxIterations limits times of iterations, unlimited if not positive
+	MaxIterations int
+	// Deadline limits duration of the whole loop measured on clock of scheduler, unlimited if not positive
+	Deadline time.Duration
+}
+
+type iterator interface {
+	iterationReports() []*RunReport
+}
+
+// SubmitLoop submit a loop node, the graph keeps acyclic as iterations run inside the node.
+// reports of iterations are in TaskReport.Iterations, and the body is drawn as a cluster in Dot.
+// an error wrapping ErrLoopLimit is returned when the limit reached while While is still true
+func (d *Scheduler[T]) SubmitLoop(name string, spec LoopSpec[T], deps ...string) error {
+	return d.SubmitLoopWithOps(name, spec, nil, deps...)
+}
+
+// SubmitLoopWithOps submit a loop node with options
+func (d *Scheduler[T]) SubmitLoopWithOps(name string, spec LoopSpec[T], ops []TaskOption, deps ...string) error {
+	if name == "" {
+		d.err = ErrNoTaskName
+		return d.err
+	}
+	if spec.Body == nil || spec.While == nil {
+		d.err = ErrNilFunc
+		return d.err
+	}
+	if spec.MaxIterations <= 0 && spec.Deadline <= 0 {
+		d.err = fmt.Errorf("%w: loop:%s has neither max iterations nor deadline", ErrInvalidOption, name)
+		return d.err
+	}
+	d.err = d.Submit(&loopTask[T]{name: name, deps: deps, spec: spec, options: ops, ds: d})
+	return d.err
+}
+
+type loopTask[T any] struct {
+	name     string
+	deps     []string
+	spec     LoopSpec[T]
+	options  []TaskOption
+	ds       *Scheduler[T]
+	viewOnce sync.Once
+	view     graphView
+	lock     sync.Mutex
+	bodies   []*Scheduler[T]
+}
+
+func (l *loopTask[T]) Name() string {
+	return l.name
+}
+
+func (l *loopTask[T]) Dependencies() []string {
+	return l.deps
+}
+
+func (l *loopTask[T]) Options() []TaskOption {
+	return l.options
+}
+
+func (l *loopTask[T]) Execute(ctx context.Context, t T) error {
+	l.lock.Lock()
+	// iterations of the last attempt are dropped when retried
+	l.bodies = nil
+	l.lock.Unlock()
+	if l.spec.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = withTimeout(ctx, l.ds.clock, l.spec.Deadline)
+		defer cancel()
+	}
+	var deadlineErr = func() error {
+		return fmt.Errorf("%w: loop:%s deadline:%s", ErrLoopLimit, l.name, l.spec.Deadline)
+	}
+	for i := 1; l.spec.While(ctx, t); i++ {
+		if l.spec.MaxIterations > 0 && i > l.spec.MaxIterations {
+			return fmt.Errorf("%w: loop:%s max iterations:%d", ErrLoopLimit, l.name, l.spec.MaxIterations)
+		}
+		if err := ctx.Err(); err != nil {
+			if err == context.DeadlineExceeded {
+				return deadlineErr()
+			}
+			return err
+		}
+		body := l.spec.Body()
+		if body == nil {
+			return fmt.Errorf("dag: loop:%s iteration:%d %w", l.name, i, ErrNilTask)
+		}
+		if body.name == "" {
+			body.name = fmt.Sprintf("%s#%d", l.name, i)
+		}
+		l.lock.Lock()
+		l.bodies = append(l.bodies, body)
+		l.lock.Unlock()
+		if err := body.Run(ctx, t); err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return deadlineErr()
+			}
+			return fmt.Errorf("dag: loop:%s iteration:%d err:%w", l.name, i, err)
+		}
+	}
+	return nil
+}
+
+func (l *loopTask[T]) iterationReports() []*RunReport {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var reports []*RunReport
+	for _, body := range l.bodies {
+		reports = append(reports, body.Report())
+	}
+	return reports
+}
+
+// subView draw body of loop, the last executed one if any, or one built once for the view
+func (l *loopTask[T]) subView() graphView {
+	l.lock.Lock()
+	var last *Scheduler[T]
+	if len(l.bodies) > 0 {
+		last = l.bodies[len(l.bodies)-1]
+	}
+	l.lock.Unlock()
+	if last != nil {
+		return last.view()
+	}
+	l.viewOnce.Do(func() {
+		if body := l.spec.Body(); body != nil {
+			l.view = body.view()
+		}
+	})
+	return l.view
+}
@@ -0,0 +1,98 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoop(t *testing.T) {
+	var polled atomic.Int64
+	var body = func() *Scheduler[any] {
+		s := NewScheduler[any]()
+		_ = s.SubmitFunc("poll", func(ctx context.Context, _ any) error {
+			polled.Add(1)
+			return nil
+		})
+		_ = s.SubmitFunc("check", func(ctx context.Context, _ any) error { return nil }, "poll")
+		return s
+	}
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitLoop("wait", LoopSpec[any]{
+		Body:          body,
+		While:         func(ctx context.Context, _ any) bool { return polled.Load() < 3 },
+		MaxIterations: 10,
+	}))
+	dot := ds.Dot()
+	for _, want := range []string{`"wait" [shape=box3d]`, `"wait/poll" -> {"wait/check"}`, `subgraph "cluster_wait"`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("want %q in dot:\n%s", want, dot)
+		}
+	}
+	checkNil(t, ds.Run(context.Background(), nil))
+	checkEqual(t, int64(3), polled.Load())
+	tr, _ := ds.Report().Task("wait")
+	checkEqual(t, 3, len(tr.Iterations))
+	checkEqual(t, 2, len(tr.Iterations[2].Tasks))
+	checkEqual(t, TaskSuccess, tr.Iterations[2].Tasks[0].Status)
+}
+
+func TestLoopLimit(t *testing.T) {
+	var body = func() *Scheduler[any] {
+		s := NewScheduler[any]()
+		_ = s.SubmitFunc("poll", func(ctx context.Context, _ any) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		})
+		return s
+	}
+	var always = func(ctx context.Context, _ any) bool { return true }
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitLoop("max", LoopSpec[any]{Body: body, While: always, MaxIterations: 2}))
+	err := ds.Run(context.Background(), nil)
+	checkEqual(t, true, errors.Is(err, ErrLoopLimit))
+	tr, _ := ds.Report().Task("max")
+	checkEqual(t, 2, len(tr.Iterations))
+
+	ds = NewScheduler[any]()
+	checkNil(t, ds.SubmitLoop("deadline", LoopSpec[any]{Body: body, While: always, Deadline: 35 * time.Millisecond}))
+	err = ds.Run(context.Background(), nil)
+	checkEqual(t, true, errors.Is(err, ErrLoopLimit))
+
+	err = NewScheduler[any]().SubmitLoop("unbounded", LoopSpec[any]{Body: body, While: always})
+	checkEqual(t, true, errors.Is(err, ErrInvalidOption))
+}
+
+func TestLoopView(t *testing.T) {
+	ds := NewScheduler[any]()
+	var built atomic.Int32
+	var iterations int
+	checkNil(t, ds.SubmitLoop("loop", LoopSpec[any]{
+		Body: func() *Scheduler[any] {
+			built.Add(1)
+			// body can use the parent scheduler when drawn
+			_ = ds.RunID()
+			s := NewScheduler[any]()
+			_ = s.SubmitFunc("step", func(ctx context.Context, _ any) error { return nil })
+			return s
+		},
+		While: func(ctx context.Context, _ any) bool {
+			iterations++
+			return iterations <= 2
+		},
+		MaxIterations: 2,
+	}))
+	dot := ds.Dot()
+	checkEqual(t, true, strings.Contains(dot, "step"))
+	checkEqual(t, dot, ds.Dot())
+	checkEqual(t, int32(1), built.Load())
+
+	// the last executed body is drawn after run
+	checkNil(t, ds.Run(context.Background(), nil))
+	checkEqual(t, int32(3), built.Load())
+	checkEqual(t, true, strings.Contains(ds.Dot(), "step"))
+	checkEqual(t, int32(3), built.Load())
+}
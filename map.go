@@ -0,0 +1,180 @@
+package dagRun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// MapSpec describes a dynamic map node: Items lists items at runtime, then a child task
+// runs Map for each item, and the optional Reduce node after it receives all results in order of items
+type MapSpec[T, I, R any] struct {
+	Name         string
+	Dependencies []string
+	Options      []TaskOption // options of the map node which lists items
+	// ItemOptions are options of each child task, eg: Retry and Timeout of an item.
+	// CircuitBreaker and RateLimit are shared by task name, so they are rejected here, set them in Options instead
+	ItemOptions []TaskOption
+	Items       func(ctx context.Context, t T) ([]I, error)
+	Map         func(ctx context.Context, t T, item I) (R, error)
+	// Concurrency limits child tasks running at the same time, unlimited if not positive
+	Concurrency int
+	// ReduceName is name of the reduce node, Name + "-reduce" by default
+	ReduceName string
+	Reduce     func(ctx context.Context, t T, results []R) error
+}
+
+// SubmitMap submit a dynamic map node, and the reduce node after it if spec.Reduce is set.
+// the map node spawns a child task named Name[i] in group Name for each item, which has its own
+// report, events, middlewares and ItemOptions, and the reduce node waits for all of them.
+// a failed child fails the run, and children starting after the run failed are canceled.
+// with a state store, results of Map are saved in json, and the map node lists items and spawns children
+// again when resumed unless reduce succeeded, so Items should return the same items then
+func SubmitMap[T, I, R any](d *Scheduler[T], spec MapSpec[T, I, R]) error {
+	if spec.Name == "" {
+		d.err = ErrNoTaskName
+		return d.err
+	}
+	if spec.Items == nil || spec.Map == nil {
+		d.err = ErrNilFunc
+		return d.err
+	}
+	// children are named by index, a breaker or limiter of each would be never shared
+	var o option
+	for _, op := range spec.ItemOptions {
+		op(&o)
+	}
+	if o.breaker != nil || o.limit != nil {
+		d.err = fmt.Errorf("%w: map task:%s CircuitBreaker or RateLimit in ItemOptions", ErrInvalidOption, spec.Name)
+		return d.err
+	}
+	if spec.Concurrency > 0 {
+		d.WithGroupConcurrency(spec.Name, spec.Concurrency)
+	}
+	m := &mapTask[T, I, R]{spec: spec, ds: d}
+	if spec.Reduce == nil {
+		return d.Submit(m)
+	}
+	return d.Submit(m, &reduceTask[T, I, R]{m: m})
+}
+
+type mapTask[T, I, R any] struct {
+	spec    MapSpec[T, I, R]
+	ds      *Scheduler[T]
+	lock    sync.Mutex
+	results []R
+}
+
+func (m *mapTask[T, I, R]) Name() string {
+	return m.spec.Name
+}
+
+func (m *mapTask[T, I, R]) Dependencies() []string {
+	return m.spec.Dependencies
+}
+
+func (m *mapTask[T, I, R]) Options() []TaskOption {
+	return m.spec.Options
+}
+
+func (m *mapTask[T, I, R]) Execute(ctx context.Context, t T) error {
+	items, err := m.spec.Items(ctx, t)
+	if err != nil {
+		return fmt.Errorf("dag: list items of map task:%s err:%w", m.Name(), err)
+	}
+	m.lock.Lock()
+	m.results = make([]R, len(items))
+	m.lock.Unlock()
+	var options = append([]TaskOption{Group(m.Name())}, m.spec.ItemOptions...)
+	var children = make([]Task[T], 0, len(items))
+	for i, item := range items {
+		children = append(children, &mapItemTask[T, I, R]{m: m, index: i, item: item, options: options})
+	}
+	var reduce string
+	if m.spec.Reduce != nil {
+		reduce = (&reduceTask[T, I, R]{m: m}).Name()
+	}
+	spawner, _ := spawnerFrom[T](ctx)
+	return m.ds.spawn(spawner, children, reduce)
+}
+
+// mapItemTask is the child task of mapTask for an item
+type mapItemTask[T, I, R any] struct {
+	m       *mapTask[T, I, R]
+	index   int
+	item    I
+	options []TaskOption
+}
+
+func (c *mapItemTask[T, I, R]) Name() string {
+	return c.m.Name() + "[" + strconv.Itoa(c.index) + "]"
+}
+
+func (c *mapItemTask[T, I, R]) Dependencies() []string {
+	return []string{c.m.Name()}
+}
+
+func (c *mapItemTask[T, I, R]) Options() []TaskOption {
+	return c.options
+}
+
+func (c *mapItemTask[T, I, R]) Execute(ctx context.Context, t T) error {
+	c.m.ds.lock.Lock()
+	failed := c.m.ds.err != nil
+	c.m.ds.lock.Unlock()
+	if failed {
+		return fmt.Errorf("%w: map task:%s item:%d", ErrCanceled, c.m.Name(), c.index)
+	}
+	r, err := c.m.spec.Map(ctx, t, c.item)
+	if err != nil {
+		return fmt.Errorf("dag: map task:%s item:%d err:%w", c.m.Name(), c.index, err)
+	}
+	c.m.lock.Lock()
+	c.m.results[c.index] = r
+	c.m.lock.Unlock()
+	return nil
+}
+
+// MarshalOutput implements Outputter, result of item is saved in json with a state store
+func (c *mapItemTask[T, I, R]) MarshalOutput(context.Context, T) ([]byte, error) {
+	c.m.lock.Lock()
+	defer c.m.lock.Unlock()
+	return json.Marshal(c.m.results[c.index])
+}
+
+// UnmarshalOutput implements Outputter, result of item is restored when resumed
+func (c *mapItemTask[T, I, R]) UnmarshalOutput(_ context.Context, _ T, data []byte) error {
+	var r R
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+	c.m.lock.Lock()
+	c.m.results[c.index] = r
+	c.m.lock.Unlock()
+	return nil
+}
+
+// reduceTask receives results of mapTask
+type reduceTask[T, I, R any] struct {
+	m *mapTask[T, I, R]
+}
+
+func (r *reduceTask[T, I, R]) Name() string {
+	if r.m.spec.ReduceName != "" {
+		return r.m.spec.ReduceName
+	}
+	return r.m.spec.Name + "-reduce"
+}
+
+func (r *reduceTask[T, I, R]) Dependencies() []string {
+	return []string{r.m.spec.Name}
+}
+
+func (r *reduceTask[T, I, R]) Execute(ctx context.Context, t T) error {
+	r.m.lock.Lock()
+	results := r.m.results
+	r.m.lock.Unlock()
+	return r.m.spec.Reduce(ctx, t, results)
+}
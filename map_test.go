@@ -0,0 +1,184 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubmitMap(t *testing.T) {
+	ds := NewScheduler[*counterCtx]()
+	checkNil(t, ds.SubmitFunc("shards", func(ctx context.Context, c *counterCtx) error {
+		c.set("shards", 5)
+		return nil
+	}))
+	var running, maxRunning atomic.Int64
+	var merged string
+	checkNil(t, SubmitMap(ds, MapSpec[*counterCtx, int, string]{
+		Name:         "process",
+		Dependencies: []string{"shards"},
+		Items: func(ctx context.Context, c *counterCtx) ([]int, error) {
+			c.lock.Lock()
+			defer c.lock.Unlock()
+			var shards []int
+			for i := 0; i < c.outputs["shards"]; i++ {
+				shards = append(shards, i)
+			}
+			return shards, nil
+		},
+		Map: func(ctx context.Context, c *counterCtx, shard int) (string, error) {
+			cur := running.Add(1)
+			defer running.Add(-1)
+			for {
+				max := maxRunning.Load()
+				if cur <= max || maxRunning.CompareAndSwap(max, cur) {
+					break
+				}
+			}
+			return "s" + strconv.Itoa(shard), nil
+		},
+		Concurrency: 2,
+		Reduce: func(ctx context.Context, c *counterCtx, results []string) error {
+			merged = strings.Join(results, ",")
+			return nil
+		},
+	}))
+	checkNil(t, ds.Run(context.Background(), newCounterCtx()))
+	checkEqual(t, "s0,s1,s2,s3,s4", merged)
+	if maxRunning.Load() > 2 {
+		t.Errorf("want max concurrency 2 but get:%d", maxRunning.Load())
+	}
+	tr, ok := ds.Report().Task("process-reduce")
+	checkEqual(t, true, ok)
+	checkEqual(t, TaskSuccess, tr.Status)
+	// children are tasks of scheduler
+	tr, ok = ds.Report().Task("process[4]")
+	checkEqual(t, true, ok)
+	checkEqual(t, TaskSuccess, tr.Status)
+	checkEqual(t, "process", tr.Group)
+}
+
+func TestSubmitMapItemOptions(t *testing.T) {
+	var tries atomic.Int32
+	var merged []int
+	rl := &recordListener{}
+	ds := NewScheduler[any]().WithListener(rl)
+	checkNil(t, SubmitMap(ds, MapSpec[any, int, int]{
+		Name:        "process",
+		ItemOptions: []TaskOption{Retry(2)},
+		Items:       func(ctx context.Context, _ any) ([]int, error) { return []int{1, 2}, nil },
+		Map: func(ctx context.Context, _ any, item int) (int, error) {
+			if item == 2 && tries.Add(1) == 1 {
+				return 0, errors.New("expect err in item 2")
+			}
+			return item * 10, nil
+		},
+		Reduce: func(ctx context.Context, _ any, results []int) error {
+			merged = results
+			return nil
+		},
+	}))
+	checkNil(t, ds.Run(context.Background(), nil))
+	checkEqual(t, 2, len(merged))
+	checkEqual(t, 20, merged[1])
+	tr, _ := ds.Report().Task("process[1]")
+	checkEqual(t, 2, tr.Attempts)
+	var events = strings.Join(rl.sorted(), "\n")
+	checkEqual(t, true, strings.Contains(events, "retry:process[1] attempt:2"))
+	checkEqual(t, true, strings.Contains(events, "end:process[0] attempts:1 err:<nil>"))
+
+	// reduce runs with no items
+	merged = nil
+	ds = NewScheduler[any]()
+	checkNil(t, SubmitMap(ds, MapSpec[any, int, int]{
+		Name:  "process",
+		Items: func(ctx context.Context, _ any) ([]int, error) { return nil, nil },
+		Map:   func(ctx context.Context, _ any, item int) (int, error) { return item, nil },
+		Reduce: func(ctx context.Context, _ any, results []int) error {
+			merged = results
+			return nil
+		},
+	}))
+	checkNil(t, ds.Run(context.Background(), nil))
+	checkEqual(t, 0, len(merged))
+}
+
+func TestSubmitMapErr(t *testing.T) {
+	ds := NewScheduler[any]()
+	var reduced bool
+	checkNil(t, SubmitMap(ds, MapSpec[any, int, int]{
+		Name:  "process",
+		Items: func(ctx context.Context, _ any) ([]int, error) { return []int{1, 2, 3}, nil },
+		Map: func(ctx context.Context, _ any, item int) (int, error) {
+			if item == 2 {
+				return 0, errors.New("expect err in item 2")
+			}
+			return item, nil
+		},
+		ReduceName: "merge",
+		Reduce: func(ctx context.Context, _ any, results []int) error {
+			reduced = true
+			return nil
+		},
+	}))
+	err := ds.Run(context.Background(), nil)
+	checkNotNil(t, err)
+	checkEqual(t, true, strings.Contains(err.Error(), "item:1"))
+	checkEqual(t, false, reduced)
+	tr, _ := ds.Report().Task("merge")
+	checkEqual(t, TaskCanceled, tr.Status)
+	tr, _ = ds.Report().Task("process[1]")
+	checkEqual(t, TaskFailed, tr.Status)
+
+	checkEqual(t, ErrNilFunc, SubmitMap(NewScheduler[any](), MapSpec[any, int, int]{Name: "process"}))
+	for _, op := range []TaskOption{CircuitBreaker(1, time.Second), RateLimit(1, time.Second)} {
+		err = SubmitMap(NewScheduler[any](), MapSpec[any, int, int]{
+			Name:        "process",
+			ItemOptions: []TaskOption{Retry(2), op},
+			Items:       func(ctx context.Context, _ any) ([]int, error) { return nil, nil },
+			Map:         func(ctx context.Context, _ any, item int) (int, error) { return item, nil },
+		})
+		checkEqual(t, true, errors.Is(err, ErrInvalidOption))
+	}
+}
+
+func TestSubmitMapResume(t *testing.T) {
+	store := NewMemoryStateStore()
+	var maps atomic.Int32
+	var reduced []int
+	var build = func(reduceErr error) *Scheduler[any] {
+		ds := NewScheduler[any]().WithStateStore(store)
+		checkNil(t, SubmitMap(ds, MapSpec[any, int, int]{
+			Name:  "process",
+			Items: func(ctx context.Context, _ any) ([]int, error) { return []int{1, 2, 3}, nil },
+			Map: func(ctx context.Context, _ any, item int) (int, error) {
+				maps.Add(1)
+				return item * 10, nil
+			},
+			Reduce: func(ctx context.Context, _ any, results []int) error {
+				reduced = results
+				return reduceErr
+			},
+		}))
+		return ds
+	}
+	first := build(errors.New("expect err in reduce"))
+	checkNotNil(t, first.Run(context.Background(), nil))
+	checkEqual(t, int32(3), maps.Load())
+
+	// results of children are restored for reduce
+	reduced = nil
+	checkNil(t, build(nil).Resume(context.Background(), first.RunID(), nil))
+	checkEqual(t, int32(3), maps.Load())
+	checkEqual(t, "[10 20 30]", fmt.Sprint(reduced))
+
+	// reduce succeeded, nothing to run
+	reduced = nil
+	checkNil(t, build(nil).Resume(context.Background(), first.RunID(), nil))
+	checkEqual(t, 0, len(reduced))
+}
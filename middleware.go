@@ -0,0 +1,102 @@
+package dagRun
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+)
+
+// Handler execute a task, the innermost handler runs task with its retries and timeout
+type Handler[T any] func(ctx context.Context, task Task[T], t T) error
+
+// Middleware wrap a Handler to do something around task execution
+type Middleware[T any] func(next Handler[T]) Handler[T]
+
+// WithMiddleware set middlewares of a task, they run inside the middlewares of scheduler.
+// the type T must be the same with the scheduler which task is submitted to
+func WithMiddleware[T any](mws ...Middleware[T]) TaskOption {
+	return func(o *option) {
+		for _, mw := range mws {
+			o.middlewares = append(o.middlewares, mw)
+		}
+	}
+}
+
+// Chain compose middlewares to one, the first one is the outermost
+func Chain[T any](mws ...Middleware[T]) Middleware[T] {
+	return func(next Handler[T]) Handler[T] {
+		for i := len(mws) - 1; i >= 0; i-- {
+			next = mws[i](next)
+		}
+		return next
+	}
+}
+
+// InjectorMiddleware convert an InjectorFactory to middleware,
+// After is called with the error of Pre when Pre fails, and the task is not executed
+func InjectorMiddleware[T any](fac InjectorFactory[T]) Middleware[T] {
+	return func(next Handler[T]) Handler[T] {
+		return func(ctx context.Context, task Task[T], t T) error {
+			inject := fac.Inject(ctx, task)
+			var err error
+			if inject.Pre != nil {
+				err = inject.Pre(ctx, t)
+			}
+			if err == nil {
+				err = next(ctx, task, t)
+			}
+			if inject.After != nil {
+				err = inject.After(ctx, t, err)
+			}
+			return err
+		}
+	}
+}
+
+// Recover turn panic of task into error
+func Recover[T any]() Middleware[T] {
+	return func(next Handler[T]) Handler[T] {
+		return func(ctx context.Context, task Task[T], t T) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("dag: task:%s panic:%v \n%s", task.Name(), r, debug.Stack())
+				}
+			}()
+			return next(ctx, task, t)
+		}
+	}
+}
+
+// Logging log start and end of task, log.Default() is used if logger is nil
+func Logging[T any](logger *log.Logger) Middleware[T] {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(next Handler[T]) Handler[T] {
+		return func(ctx context.Context, task Task[T], t T) error {
+			start := time.Now()
+			logger.Printf("dag: task:%s start", task.Name())
+			err := next(ctx, task, t)
+			if err != nil {
+				logger.Printf("dag: task:%s end, cost:%s, err:%v", task.Name(), time.Since(start), err)
+			} else {
+				logger.Printf("dag: task:%s end, cost:%s", task.Name(), time.Since(start))
+			}
+			return err
+		}
+	}
+}
+
+// Timing report the running time and error of each task to observe
+func Timing[T any](observe func(task string, cost time.Duration, err error)) Middleware[T] {
+	return func(next Handler[T]) Handler[T] {
+		return func(ctx context.Context, task Task[T], t T) error {
+			start := time.Now()
+			err := next(ctx, task, t)
+			observe(task.Name(), time.Since(start), err)
+			return err
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package dagRun
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func orderMiddleware(name string, order *[]string, lock *sync.Mutex) Middleware[*sync.Map] {
+	return func(next Handler[*sync.Map]) Handler[*sync.Map] {
+		return func(ctx context.Context, task Task[*sync.Map], t *sync.Map) error {
+			lock.Lock()
+			*order = append(*order, name+" before "+task.Name())
+			lock.Unlock()
+			err := next(ctx, task, t)
+			lock.Lock()
+			*order = append(*order, name+" after "+task.Name())
+			lock.Unlock()
+			return err
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var order []string
+	var lock sync.Mutex
+	ds := NewScheduler[*sync.Map]().Use(orderMiddleware("m1", &order, &lock), orderMiddleware("m2", &order, &lock))
+	checkNil(t, ds.Submit(task{name: "T1", options: []TaskOption{
+		WithMiddleware(orderMiddleware("t1", &order, &lock)),
+	}}))
+	checkNil(t, ds.Run(context.Background(), &sync.Map{}))
+	want := []string{"m1 before T1", "m2 before T1", "t1 before T1", "t1 after T1", "m2 after T1", "m1 after T1"}
+	checkEqual(t, len(want), len(order))
+	for i := range want {
+		checkEqual(t, want[i], order[i])
+	}
+}
+
+func TestMiddlewareTypeMismatch(t *testing.T) {
+	ds := NewScheduler[*sync.Map]()
+	err := ds.Submit(task{name: "T1", options: []TaskOption{WithMiddleware(Recover[int]())}})
+	checkEqual(t, true, errors.Is(err, ErrInvalidOption))
+	checkEqual(t, true, errors.Is(ds.Run(context.Background(), &sync.Map{}), ErrInvalidOption))
+}
+
+func TestMiddlewareWrapsRetry(t *testing.T) {
+	var tries, calls int
+	var cost time.Duration
+	ds := NewScheduler[*sync.Map]().Use(Timing[*sync.Map](func(task string, d time.Duration, err error) {
+		calls++
+		cost = d
+	}))
+	checkNil(t, ds.SubmitFuncWithOps("T1", func(ctx context.Context, m *sync.Map) error {
+		tries++
+		time.Sleep(10 * time.Millisecond)
+		if tries < 3 {
+			return errors.New("retry")
+		}
+		return nil
+	}, []TaskOption{Retry(3)}))
+	checkNil(t, ds.Run(context.Background(), &sync.Map{}))
+	checkEqual(t, 3, tries)
+	checkEqual(t, 1, calls)
+	checkGreater(t, cost.Milliseconds(), int64(29))
+}
+
+func TestRecoverAndLogging(t *testing.T) {
+	var buf bytes.Buffer
+	ds := NewScheduler[*sync.Map]().Use(Logging[*sync.Map](log.New(&buf, "", 0)), Recover[*sync.Map]())
+	checkNil(t, ds.SubmitFunc("T1", func(ctx context.Context, m *sync.Map) error {
+		panic("expect panic in T1")
+	}))
+	err := ds.Run(context.Background(), &sync.Map{})
+	checkEqual(t, true, strings.HasPrefix(err.Error(), "dag: task:T1 panic:expect panic in T1"))
+	checkEqual(t, true, strings.HasPrefix(buf.String(), "dag: task:T1 start\ndag: task:T1 end, cost:"))
+	checkEqual(t, true, strings.Contains(buf.String(), "err:dag: task:T1 panic:expect panic in T1"))
+}
+
+func TestInjectorAfterCalledOnPreError(t *testing.T) {
+	var executed bool
+	var afterErr error
+	ds := NewWithInjectorFactory[*sync.Map](InjectorFactoryFunc[*sync.Map](func(ctx context.Context, task Task[*sync.Map]) Injector[*sync.Map] {
+		return Injector[*sync.Map]{
+			Pre: func(ctx context.Context, runCtx *sync.Map) error {
+				return errors.New("expect err in pre")
+			},
+			After: func(ctx context.Context, runCtx *sync.Map, err error) error {
+				afterErr = err
+				return err
+			},
+		}
+	}))
+	checkNil(t, ds.SubmitFunc("T1", func(ctx context.Context, m *sync.Map) error {
+		executed = true
+		return nil
+	}))
+	err := ds.Run(context.Background(), &sync.Map{})
+	checkEqual(t, "expect err in pre", err.Error())
+	checkEqual(t, "expect err in pre", afterErr.Error())
+	checkEqual(t, false, executed)
+}
@@ -5,8 +5,17 @@ import "time"
 type TaskOption func(*option)
 
 type option struct {
-	retry   int
-	timeout time.Duration
+	retry       int
+	timeout     time.Duration
+	group       string
+	middlewares []any
+	estimate    time.Duration
+	compensate  any
+	always      bool
+	fallback    any
+	breaker     *breakerOption
+	limit       *limitOption
+	hedge       time.Duration
 }
 
 // Retry set task max retry times
@@ -22,3 +31,22 @@ func Timeout(timeout time.Duration) TaskOption {
 		o.timeout = timeout
 	}
 }
+
+// Group set the group(stage) of task, eg: "fetch", "transform", "persist".
+// tasks in the same group are drawn in one cluster by Dot, share the group
+// limits of scheduler and are aggregated together in RunReport
+func Group(name string) TaskOption {
+	return func(o *option) {
+		o.group = name
+	}
+}
+
+func taskOption(task any) option {
+	var o option
+	if opT, ok := task.(Optioned); ok {
+		for _, op := range opT.Options() {
+			op(&o)
+		}
+	}
+	return o
+}
@@ -0,0 +1,231 @@
+package dagRun
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sources of estimated duration of task in plan
+const (
+	EstimateNone     = ""
+	EstimateDeclared = "declared"
+	EstimateHistory  = "history"
+)
+
+// EstimatedDuration declare how long task usually runs, used by Scheduler.Plan
+func EstimatedDuration(duration time.Duration) TaskOption {
+	return func(o *option) {
+		o.estimate = duration
+	}
+}
+
+// PlanOption is option of Scheduler.Plan
+type PlanOption func(*planOption)
+
+type planOption struct {
+	history *RunReport
+}
+
+// WithPlanHistory estimate durations of tasks by a report of past run,
+// for tasks without EstimatedDuration
+func WithPlanHistory(report *RunReport) PlanOption {
+	return func(o *planOption) {
+		o.history = report
+	}
+}
+
+// Plan is how tasks would execute: tasks run level by level, a level starts after all tasks of
+// the previous level finished
+type Plan struct {
+	Levels   []PlanLevel
+	Makespan time.Duration // estimated duration of whole run, sum of durations of levels
+}
+
+// PlanLevel is tasks running in parallel
+type PlanLevel struct {
+	Tasks    []PlanTask // sorted by name
+	Duration time.Duration
+}
+
+// PlanTask is a task in plan
+type PlanTask struct {
+	Name         string
+	Group        string
+	Dependencies []string
+	Level        int
+	// Branch means task is a branch task, tasks after it are skipped when its branch is invalid
+	Branch bool
+	// Conditional means task is after a branch task, it may be skipped
+	Conditional bool
+	// AlwaysRun means task runs even if its dependencies failed or skipped
+	AlwaysRun      bool
+	Estimate       time.Duration
+	EstimateSource string
+}
+
+// Task return task in plan by name
+func (p *Plan) Task(name string) (PlanTask, bool) {
+	for _, l := range p.Levels {
+		for _, t := range l.Tasks {
+			if t.Name == name {
+				return t, true
+			}
+		}
+	}
+	return PlanTask{}, false
+}
+
+// String dump plan in text, one line for each level, conditional tasks are suffixed with "?"
+// and branch tasks with "*", eg:
+//
+//	level 0 [1s]: A, B*
+//	level 1 [2s]: C?
+//	makespan: 3s
+func (p *Plan) String() string {
+	var sb strings.Builder
+	for i, l := range p.Levels {
+		var names []string
+		for _, t := range l.Tasks {
+			name := t.Name
+			if t.Branch {
+				name += "*"
+			}
+			if t.Conditional {
+				name += "?"
+			}
+			names = append(names, name)
+		}
+		sb.WriteString(fmt.Sprintf("level %d [%s]: %s\n", i, l.Duration, strings.Join(names, ", ")))
+	}
+	sb.WriteString(fmt.Sprintf("makespan: %s\n", p.Makespan))
+	return sb.String()
+}
+
+// Plan simulates Run without executing any task, return the levels in which tasks would execute
+// and the estimated makespan, tasks without estimated duration are counted as zero
+func (d *Scheduler[T]) Plan(ops ...PlanOption) (*Plan, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	var po planOption
+	for _, op := range ops {
+		op(&po)
+	}
+	inDegrees := make(map[string]int, len(d.nodes))
+	next := make(map[string][]string, len(d.nodes))
+	for name := range d.nodes {
+		inDegrees[name] = 0
+	}
+	for name, n := range d.nodes {
+		for _, dep := range n.task.Dependencies() {
+			if _, ok := d.nodes[dep]; !ok {
+				return nil, fmt.Errorf("dag:%w: task :%s's dependency:%s not found", ErrTaskNotExist, name, dep)
+			}
+			inDegrees[name]++
+			next[dep] = append(next[dep], name)
+		}
+	}
+	var level []string
+	for name, inDegree := range inDegrees {
+		if inDegree == 0 {
+			level = append(level, name)
+		}
+	}
+	var plan Plan
+	var conditional = map[string]bool{}
+	var visited int
+	for len(level) > 0 {
+		sort.Strings(level)
+		var pl PlanLevel
+		var nextLevel []string
+		for _, name := range level {
+			n := d.nodes[name]
+			_, branch := n.task.(Conditioned[T])
+			pt := PlanTask{
+				Name:         name,
+				Group:        n.opt.group,
+				Dependencies: append([]string(nil), n.task.Dependencies()...),
+				Level:        len(plan.Levels),
+				Branch:       branch,
+				Conditional:  conditional[name] && !n.opt.always,
+				AlwaysRun:    n.opt.always,
+			}
+			pt.Estimate, pt.EstimateSource = n.estimate(po.history)
+			pl.Tasks = append(pl.Tasks, pt)
+			for _, to := range next[name] {
+				if branch || pt.Conditional {
+					conditional[to] = true
+				}
+				inDegrees[to]--
+				if inDegrees[to] == 0 {
+					nextLevel = append(nextLevel, to)
+				}
+			}
+			visited++
+		}
+		pl.Duration = d.levelDuration(pl.Tasks)
+		plan.Makespan += pl.Duration
+		plan.Levels = append(plan.Levels, pl)
+		level = nextLevel
+	}
+	if visited < len(d.nodes) {
+		var circleNodes []string
+		for name, inDegree := range inDegrees {
+			if inDegree != 0 {
+				circleNodes = append(circleNodes, name)
+			}
+		}
+		sort.Strings(circleNodes)
+		return nil, fmt.Errorf("dag:graph has circle in nodes:%v", circleNodes)
+	}
+	return &plan, nil
+}
+
+// estimate return estimated duration of task and where it comes from
+func (n *node[T]) estimate(history *RunReport) (time.Duration, string) {
+	if n.opt.estimate > 0 {
+		return n.opt.estimate, EstimateDeclared
+	}
+	if history != nil {
+		if tr, ok := history.Task(n.Name()); ok && tr.Status == TaskSuccess {
+			return tr.Duration(), EstimateHistory
+		}
+	}
+	return 0, EstimateNone
+}
+
+// levelDuration return estimated duration of a level, tasks in a group with concurrency limit
+// are scheduled longest first on the limited workers
+func (d *Scheduler[T]) levelDuration(tasks []PlanTask) time.Duration {
+	var longest time.Duration
+	byGroup := map[string][]time.Duration{}
+	for _, t := range tasks {
+		if g := d.groups[t.Group]; g != nil && g.concurrency > 0 {
+			byGroup[t.Group] = append(byGroup[t.Group], t.Estimate)
+		} else if t.Estimate > longest {
+			longest = t.Estimate
+		}
+	}
+	for group, durations := range byGroup {
+		sort.Slice(durations, func(i, j int) bool {
+			return durations[i] > durations[j]
+		})
+		workers := make([]time.Duration, d.groups[group].concurrency)
+		for _, duration := range durations {
+			// the worker finishing first takes the next task
+			first := 0
+			for i := range workers {
+				if workers[i] < workers[first] {
+					first = i
+				}
+			}
+			workers[first] += duration
+			if workers[first] > longest {
+				longest = workers[first]
+			}
+		}
+	}
+	return longest
+}
@@ -0,0 +1,69 @@
+package dagRun
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPlan(t *testing.T) {
+	ds := NewFuncScheduler()
+	var nop = func() error { return nil }
+	ds.SubmitWithOps("A", nop, []TaskOption{EstimatedDuration(time.Second)}).
+		SubmitWithOps("B", nop, []TaskOption{EstimatedDuration(2 * time.Second)}).
+		SubmitBranch("C", func() (bool, error) { return true, nil }, "A").
+		SubmitWithOps("D", nop, []TaskOption{EstimatedDuration(3 * time.Second)}, "C", "B").
+		Submit("E", nop, "B")
+	plan, err := ds.Plan()
+	checkNil(t, err)
+	checkEqual(t, 3, len(plan.Levels))
+	checkEqual(t, 2*time.Second, plan.Levels[0].Duration)
+	checkEqual(t, 5*time.Second, plan.Makespan)
+	d, ok := plan.Task("D")
+	checkEqual(t, true, ok)
+	checkEqual(t, 2, d.Level)
+	checkEqual(t, true, d.Conditional)
+	checkEqual(t, EstimateDeclared, d.EstimateSource)
+	c, _ := plan.Task("C")
+	checkEqual(t, true, c.Branch)
+	checkEqual(t, false, c.Conditional)
+	e, _ := plan.Task("E")
+	checkEqual(t, false, e.Conditional)
+	checkEqual(t, "level 0 [2s]: A, B\nlevel 1 [0s]: C*, E\nlevel 2 [3s]: D?\nmakespan: 5s\n", plan.String())
+	// nothing executed
+	checkEqual(t, TaskPending, ds.Report().Tasks[0].Status)
+}
+
+func TestPlanHistoryAndGroup(t *testing.T) {
+	ds := NewScheduler[*sync.Map]().WithGroupConcurrency("io", 2)
+	checkNil(t, ds.Submit(
+		task{name: "A", options: []TaskOption{Group("io"), EstimatedDuration(3 * time.Second)}},
+		task{name: "B", options: []TaskOption{Group("io"), EstimatedDuration(2 * time.Second)}},
+		task{name: "C", options: []TaskOption{Group("io"), EstimatedDuration(2 * time.Second)}},
+		task{name: "D", dependencies: []string{"A"}},
+	))
+	start := time.Now()
+	history := &RunReport{Tasks: []TaskReport{
+		{Name: "D", Status: TaskSuccess, Start: start, End: start.Add(time.Second)},
+	}}
+	plan, err := ds.Plan(WithPlanHistory(history))
+	checkNil(t, err)
+	// A on one worker, B and C on the other
+	checkEqual(t, 4*time.Second, plan.Levels[0].Duration)
+	d, _ := plan.Task("D")
+	checkEqual(t, EstimateHistory, d.EstimateSource)
+	checkEqual(t, 5*time.Second, plan.Makespan)
+}
+
+func TestPlanCircle(t *testing.T) {
+	ds := NewScheduler[*sync.Map]()
+	checkNil(t, ds.Submit(
+		task{name: "A", dependencies: []string{"B"}},
+		task{name: "B", dependencies: []string{"A"}},
+	))
+	_, err := ds.Plan()
+	checkNotNil(t, err)
+	checkNil(t, ds.Submit(task{name: "C", dependencies: []string{"X"}}))
+	_, err = ds.Plan()
+	checkNotNil(t, err)
+}
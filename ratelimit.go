@@ -0,0 +1,117 @@
+package dagRun
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// limitOption is the config of rate limit set by option
+type limitOption struct {
+	n   int
+	per time.Duration
+}
+
+// RateLimit limit executions of tasks with the same name in the process to n per duration,
+// eg: RateLimit(50, time.Second). it's a token bucket holding at most n tokens measured on clock
+// of scheduler, each attempt waits for a token respecting ctx, and the wait is in TaskReport.Throttled
+// which is not counted in Duration. tasks with the same name must have the same rate limit
+func RateLimit(n int, per time.Duration) TaskOption {
+	return func(o *option) {
+		o.limit = &limitOption{n: n, per: per}
+	}
+}
+
+// limiters are token buckets by task name
+var limiters sync.Map
+
+type limiter struct {
+	o      limitOption
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// limiterOf return token bucket of task, error if it's built by another rate limit
+func limiterOf(name string, o *limitOption, now time.Time) (*limiter, error) {
+	v, _ := limiters.LoadOrStore(name, &limiter{o: *o, tokens: float64(o.n), last: now})
+	l := v.(*limiter)
+	if l.o != *o {
+		return nil, fmt.Errorf("%w: task:%s rate limit:%d per:%s conflicts with rate limit:%d per:%s of the same name",
+			ErrInvalidOption, name, o.n, o.per, l.o.n, l.o.per)
+	}
+	return l, nil
+}
+
+// reserve take a token if any, or return time to wait for the next one
+func (l *limiter) reserve(now time.Time) (time.Duration, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	o := l.o
+	rate := float64(o.n) / float64(o.per)
+	if now.After(l.last) {
+		l.tokens += float64(now.Sub(l.last)) * rate
+		if l.tokens > float64(o.n) {
+			l.tokens = float64(o.n)
+		}
+		l.last = now
+	}
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+	wait := time.Duration((1 - l.tokens) / rate)
+	if wait <= 0 {
+		wait = 1
+	}
+	return wait, false
+}
+
+// tryToken take a token of rate limit of node without waiting, return false if none
+func (n *node[T]) tryToken() bool {
+	o := n.opt.limit
+	if o == nil {
+		return true
+	}
+	now := n.ds.clock.Now()
+	l, err := limiterOf(n.Name(), o, now)
+	if err != nil {
+		return false
+	}
+	_, ok := l.reserve(now)
+	return ok
+}
+
+// throttle wait for a token of rate limit of node
+func (n *node[T]) throttle(ctx context.Context) error {
+	o := n.opt.limit
+	if o == nil {
+		return nil
+	}
+	clock := n.ds.clock
+	start := clock.Now()
+	l, err := limiterOf(n.Name(), o, start)
+	if err != nil {
+		return err
+	}
+	wait, ok := l.reserve(start)
+	if ok {
+		return nil
+	}
+	defer func() {
+		n.mu.Lock()
+		n.report.Throttled += clock.Now().Sub(start)
+		n.mu.Unlock()
+	}()
+	for ; !ok; wait, ok = l.reserve(clock.Now()) {
+		timer := clock.NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	const name = "TestRateLimit"
+	defer limiters.Delete(name)
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	var reports = make([]TaskReport, 6)
+	start := time.Now()
+	for i := range reports {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ds := NewScheduler[any]()
+			checkNil(t, ds.SubmitFuncWithOps(name, func(ctx context.Context, _ any) error {
+				calls.Add(1)
+				return nil
+			}, []TaskOption{RateLimit(2, 100*time.Millisecond)}))
+			checkNil(t, ds.Run(context.Background(), nil))
+			reports[i], _ = ds.Report().Task(name)
+		}(i)
+	}
+	wg.Wait()
+	// 2 tokens at first, and then 1 token per 50ms
+	checkEqual(t, int32(6), calls.Load())
+	checkGreater(t, int64(time.Since(start)), int64(190*time.Millisecond))
+	var throttled int
+	for _, r := range reports {
+		if r.Throttled > 0 {
+			throttled++
+			// wait is not counted in execution
+			checkGreater(t, int64(r.Throttled), int64(r.Duration()))
+		}
+	}
+	checkEqual(t, 4, throttled)
+}
+
+func TestRateLimitCanceled(t *testing.T) {
+	const name = "TestRateLimitCanceled"
+	defer limiters.Delete(name)
+	var calls int
+	var run = func(ctx context.Context, ops ...TaskOption) (TaskReport, error) {
+		ds := NewScheduler[any]()
+		checkNil(t, ds.SubmitFuncWithOps(name, func(ctx context.Context, _ any) error {
+			calls++
+			return errors.New("expect err")
+		}, append([]TaskOption{RateLimit(2, time.Hour)}, ops...)))
+		err := ds.Run(ctx, nil)
+		r, _ := ds.Report().Task(name)
+		return r, err
+	}
+	// each attempt takes a token
+	r, err := run(context.Background(), Retry(2))
+	checkNotNil(t, err)
+	checkEqual(t, 2, r.Attempts)
+	checkEqual(t, 2, calls)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	r, err = run(ctx)
+	checkEqual(t, true, errors.Is(err, context.DeadlineExceeded))
+	checkEqual(t, 0, r.Attempts)
+	checkEqual(t, 2, calls)
+	checkGreater(t, int64(r.Throttled), int64(10*time.Millisecond))
+
+	err = NewScheduler[any]().SubmitFuncWithOps("T", func(ctx context.Context, _ any) error { return nil },
+		[]TaskOption{RateLimit(1, 0)})
+	checkEqual(t, true, errors.Is(err, ErrInvalidOption))
+
+	// tasks with the same name share the rate limit
+	err = NewScheduler[any]().SubmitFuncWithOps(name, func(ctx context.Context, _ any) error { return nil },
+		[]TaskOption{RateLimit(3, time.Hour)})
+	checkEqual(t, true, errors.Is(err, ErrInvalidOption))
+}
@@ -2,24 +2,40 @@ package dagRun
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 )
 
 type Named interface{ Name() string }
 
 type Registry[T Named] struct {
-	mm map[string]T
+	mm     map[string]T
+	logger *slog.Logger
 }
 
 func NewRegistry[T Named]() Registry[T] {
 	return Registry[T]{mm: make(map[string]T)}
 }
 
+// WithLogger return registry logs with logger, slog.Default() is used if not set
+func (l Registry[T]) WithLogger(logger *slog.Logger) Registry[T] {
+	l.logger = logger
+	return l
+}
+
+func (l Registry[T]) log() *slog.Logger {
+	if l.logger == nil {
+		return slog.Default()
+	}
+	return l.logger
+}
+
 func (l Registry[T]) Register(t T) {
 	if _, ok := l.mm[t.Name()]; ok {
-		log.Panicf("duplicate register name:%s", t.Name())
+		l.log().Error("dagRun: duplicate register", "name", t.Name())
+		panic(fmt.Sprintf("duplicate register name:%s", t.Name()))
 	}
 	l.mm[t.Name()] = t
+	l.log().Debug("dagRun: register", "name", t.Name())
 }
 
 func (l Registry[T]) Get(name string) (T, error) {
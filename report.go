@@ -0,0 +1,130 @@
+package dagRun
+
+import (
+	"sort"
+	"time"
+)
+
+// TaskStatus is the status of a task in a run
+type TaskStatus string
+
+const (
+	TaskPending     TaskStatus = "pending"
+	TaskRunning     TaskStatus = "running"
+	TaskSuccess     TaskStatus = "success"
+	TaskFailed      TaskStatus = "failed"
+	TaskSkipped     TaskStatus = "skipped"
+	TaskCanceled    TaskStatus = "canceled"
+	TaskRestored    TaskStatus = "restored"    // restored from checkpoint when resumed
+	TaskCached      TaskStatus = "cached"      // output restored from cache, not executed
+	TaskCompensated TaskStatus = "compensated" // succeeded and then compensated as run failed
+	TaskFallback    TaskStatus = "fallback"    // failed and then fallback succeeded
+)
+
+// TaskReport records how a task ran
+type TaskReport struct {
+	Name     string
+	Group    string
+	Status   TaskStatus
+	Attempts int
+	Ready    time.Time // time when all dependencies finished
+	Start    time.Time
+	End      time.Time
+	Err      error
+	// Throttled is the time waited for tokens of rate limit, which is not counted in Duration
+	Throttled time.Duration
+	// Hedged is times of hedged executions started, and HedgeWins is times they won
+	Hedged    int
+	HedgeWins int
+	Sub       *RunReport // report of the sub dag when task is a sub dag
+	// Iterations are reports of each iteration when task is a loop
+	Iterations []*RunReport
+}
+
+// Wait return the time task waited from ready to start, eg: for the group limit
+func (t TaskReport) Wait() time.Duration {
+	if t.Ready.IsZero() || t.Start.IsZero() {
+		return 0
+	}
+	return t.Start.Sub(t.Ready)
+}
+
+// Duration return the running time of task except Throttled, zero if task not finished
+func (t TaskReport) Duration() time.Duration {
+	if t.Start.IsZero() || t.End.IsZero() {
+		return 0
+	}
+	return t.End.Sub(t.Start) - t.Throttled
+}
+
+// RunReport records how all tasks of a scheduler ran, Tasks are sorted by name
+type RunReport struct {
+	RunID string
+	Start time.Time
+	End   time.Time
+	Err   error
+	Tasks []TaskReport
+}
+
+// Duration return the running time of the whole run, zero if run not finished
+func (r *RunReport) Duration() time.Duration {
+	if r.Start.IsZero() || r.End.IsZero() {
+		return 0
+	}
+	return r.End.Sub(r.Start)
+}
+
+// Task get report of task by name
+func (r *RunReport) Task(name string) (TaskReport, bool) {
+	i := sort.Search(len(r.Tasks), func(i int) bool { return r.Tasks[i].Name >= name })
+	if i < len(r.Tasks) && r.Tasks[i].Name == name {
+		return r.Tasks[i], true
+	}
+	return TaskReport{}, false
+}
+
+// GroupReport aggregates reports of tasks in the same group
+type GroupReport struct {
+	Group    string
+	Start    time.Time
+	End      time.Time
+	Tasks    []TaskReport
+	Statuses map[TaskStatus]int
+}
+
+// Duration return time from the first task start to the last task end of group
+func (g GroupReport) Duration() time.Duration {
+	if g.Start.IsZero() || g.End.IsZero() {
+		return 0
+	}
+	return g.End.Sub(g.Start)
+}
+
+// Groups aggregate task reports by group, sorted by group name.
+// tasks without group are aggregated in group ""
+func (r *RunReport) Groups() []GroupReport {
+	var byName = map[string]*GroupReport{}
+	var names []string
+	for _, t := range r.Tasks {
+		g, ok := byName[t.Group]
+		if !ok {
+			g = &GroupReport{Group: t.Group, Statuses: map[TaskStatus]int{}}
+			byName[t.Group] = g
+			names = append(names, t.Group)
+		}
+		g.Tasks = append(g.Tasks, t)
+		g.Statuses[t.Status]++
+		if !t.Start.IsZero() && (g.Start.IsZero() || t.Start.Before(g.Start)) {
+			g.Start = t.Start
+		}
+		if t.End.After(g.End) {
+			g.End = t.End
+		}
+	}
+	sort.Strings(names)
+	groups := make([]GroupReport, 0, len(names))
+	for _, name := range names {
+		groups = append(groups, *byName[name])
+	}
+	return groups
+}
@@ -0,0 +1,47 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRunReport(t *testing.T) {
+	scd := NewFuncScheduler()
+	err := scd.
+		Submit("T1", func() error { return nil }).
+		Submit("T2", func() error { return errors.New("expect err in T2") }, "T1").
+		Submit("T3", func() error { return nil }, "T2").
+		Submit("T4", func() error { return nil }, "B1").
+		SubmitBranch("B1", func() (bool, error) { return false, nil }).
+		Run()
+	checkEqual(t, "expect err in T2", err.Error())
+	report := scd.Report()
+	checkEqual(t, err.Error(), report.Err.Error())
+	checkEqual(t, 5, len(report.Tasks))
+	wants := map[string]TaskStatus{
+		"B1": TaskSuccess,
+		"T1": TaskSuccess,
+		"T2": TaskFailed,
+		"T3": TaskCanceled,
+		"T4": TaskSkipped,
+	}
+	for name, status := range wants {
+		tr, ok := report.Task(name)
+		checkEqual(t, true, ok)
+		if tr.Status != status {
+			t.Errorf("task:%s want status:%s but get:%s", name, status, tr.Status)
+		}
+	}
+	_, ok := report.Task("T5")
+	checkEqual(t, false, ok)
+	checkGreater(t, int64(report.Duration()), 0)
+}
+
+func TestRunReportBeforeRun(t *testing.T) {
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitFunc("T1", func(ctx context.Context, a any) error { return nil }))
+	report := ds.Report()
+	checkEqual(t, TaskPending, report.Tasks[0].Status)
+	checkEqual(t, 0, int(report.Duration()))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/url"
 	"runtime/debug"
 	"sort"
@@ -14,6 +15,7 @@ import (
 
 // Scheduler simple scheduler for typed tasks
 type Scheduler[T any] struct {
+	name        string
 	dag         *Graph
 	nodes       map[string]*node[T]
 	swg         *sync.WaitGroup
@@ -22,6 +24,32 @@ type Scheduler[T any] struct {
 	injectorFac InjectorFactory[T]
 	sealed      bool
 	done        chan error
+	groups      map[string]*groupLimit
+	listeners   Listeners
+	middlewares []Middleware[T]
+	start       time.Time
+	end         time.Time
+	runErr      error
+	runID       string
+	logger      *slog.Logger
+	logging     bool // logListener is added
+	store       StateStore
+	cache       Cache
+	clock       Clock
+	running     bool
+	// inDegrees counts unfinished dependencies of nodes, spawned are nodes added while running
+	inDegrees map[string]int
+	spawned   []*node[T]
+	// states are checkpoints loaded by Resume, which restore spawned tasks
+	states    map[string]*TaskState
+	completed int
+}
+
+// groupLimit limits tasks in the same group
+type groupLimit struct {
+	concurrency int
+	timeout     time.Duration
+	sem         chan struct{}
 }
 
 // Task is the interface all your tasks should implement
@@ -55,10 +83,32 @@ type ConditionBranch[T any] interface {
 }
 
 type node[T any] struct {
-	ds       *Scheduler[T]
-	next     []*node[T]
-	task     Task[T]
+	ds     *Scheduler[T]
+	next   []*node[T]
+	task   Task[T]
+	opt    option
+	handle Handler[T]
+	// deadlineSource tells where timeout of opt comes from
+	deadlineSource string
+	// restored is the checkpoint of task when resumed
+	restored *TaskState
+	// cached is true when output of task restored from cache
+	cached bool
+	// fellBack is true when task failed and fallback succeeded
+	fellBack bool
 	preBreak atomic.Int64
+	// done, broke and order are guarded by lock of scheduler
+	done  bool
+	broke bool
+	order int // order of completion
+	// checkpoint of node waits for tasks it spawned, owners are nodes waiting for it,
+	// unsaved is true when node succeeded and its checkpoint is waiting. guarded by lock of scheduler
+	waiting int
+	owners  []*node[T]
+	unsaved bool
+	valid   bool
+	mu      sync.Mutex
+	report  TaskReport
 }
 
 func (n *node[T]) Name() string {
@@ -71,104 +121,250 @@ func (n *node[T]) start(ctx context.Context, t T) {
 		var breakNext bool
 		defer func() {
 			if pErr := recover(); pErr != nil {
-				n.ds.CancelWithErr(fmt.Errorf("dag: panic:%v \n%s", pErr, debug.Stack()))
-			}
-			if err != nil {
-				n.ds.CancelWithErr(err)
+				err = fmt.Errorf("dag: panic:%v \n%s", pErr, debug.Stack())
 			}
-			if breakNext {
-				n.breakNext()
-			} else {
+			var valid = !breakNext
+			if valid && n.restored != nil {
+				valid = n.restored.Valid
+			} else if valid {
 				// when task is a branch node
 				if ct, ok := n.task.(Conditioned[T]); ok {
-					if valid := ct.ValidBranch(ctx, t); !valid {
-						n.breakNext()
-					}
+					valid = ct.ValidBranch(ctx, t)
 				}
 			}
+			// output of fallback is not saved, so task runs again when resumed
+			if err == nil && !n.fellBack && n.ds.store != nil {
+				err = n.checkpoint(ctx, t, valid, breakNext)
+			}
+			if err != nil {
+				n.ds.CancelWithErr(err)
+			}
+			n.finish(ctx, breakNext, err)
+			n.complete(!valid)
 			n.ds.swg.Done()
 		}()
 		//  break next nodes on this branch
-		if n.preBreak.Load() == 0 {
+		if n.preBreak.Load() == 0 && !n.opt.always {
 			breakNext = true
 			return
 		}
-		var execute = func() {
-			var o option
-			opT, ok := n.task.(Optioned)
-			if ok {
-				ops := opT.Options()
-				for _, op := range ops {
-					op(&o)
-				}
-			}
-			err = n.executeTask(ctx, n.task, t, o)
+		if n.restored != nil {
+			err = n.restore(ctx, t)
+			return
 		}
-		if n.ds.injectorFac != nil {
-			inject := n.ds.injectorFac.Inject(ctx, n.task)
-			if inject.Pre != nil {
-				err = inject.Pre(ctx, t)
-				if err != nil {
-					return
-				}
+		n.ready()
+		n.ds.listeners.OnTaskReady(ctx, n.event(0, nil))
+		if g := n.ds.groups[n.opt.group]; g != nil && g.sem != nil {
+			select {
+			case g.sem <- struct{}{}:
+				defer func() { <-g.sem }()
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
 			}
-			execute()
-			if inject.After != nil {
-				err = inject.After(ctx, t, err)
+		}
+		key, err := n.cacheKey(ctx, t)
+		if err != nil {
+			return
+		}
+		n.running()
+		if key != "" {
+			if n.cached, err = n.loadCache(ctx, t, key); err != nil || n.cached {
+				return
 			}
-		} else {
-			execute()
+		}
+		if err = n.execute(ctx, t); err != nil {
+			err = n.runFallback(ctx, t, err)
+		} else if key != "" {
+			err = n.saveCache(ctx, t, key)
 		}
 	}()
 }
 
+// chain build the handler of node: middlewares of scheduler, injector, middlewares of task, and then execute task
+func (n *node[T]) chain() Handler[T] {
+	var mws = append([]Middleware[T](nil), n.ds.middlewares...)
+	if n.ds.injectorFac != nil {
+		mws = append(mws, InjectorMiddleware(n.ds.injectorFac))
+	}
+	for _, mw := range n.opt.middlewares {
+		mws = append(mws, mw.(Middleware[T]))
+	}
+	return Chain(mws...)(func(ctx context.Context, task Task[T], t T) error {
+		return n.executeTask(ctx, task, t, n.opt)
+	})
+}
+
 func (n *node[T]) executeTask(ctx context.Context, task Task[T], t T, op option) error {
-	var err error
-	var runWithRetry = func() {
+	info := n.info(ctx, op)
+	if op.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = withTimeout(ctx, n.ds.clock, op.timeout)
+		defer cancel()
+	}
+	// timedOut guards that no attempt starts after task timeout
+	var (
+		lock     sync.Mutex
+		timedOut bool
+	)
+	var runWithRetry = func() (err error) {
 		if op.retry < 1 {
 			op.retry = 1
 		}
 		for i := 0; i < op.retry; i++ {
-			err = task.Execute(ctx, t)
+			// no retry after task timeout or canceled
+			if i > 0 && ctx.Err() != nil {
+				break
+			}
+			if tErr := n.throttle(ctx); tErr != nil {
+				return tErr
+			}
+			lock.Lock()
+			if timedOut {
+				lock.Unlock()
+				break
+			}
+			n.attempt(i + 1)
+			if i == 0 {
+				n.ds.listeners.OnTaskStart(ctx, n.event(i+1, nil))
+			} else {
+				n.ds.listeners.OnTaskRetry(ctx, n.event(i+1, err))
+			}
+			lock.Unlock()
+			err = n.executeAttempt(ctx, task, t, info, i+1, op.hedge)
 			if err == nil {
 				break
 			}
 		}
+		return err
 	}
 	if op.timeout > 0 {
-		var done = make(chan struct{}, 1)
+		var done = make(chan error, 1)
 		go func() {
+			var err error
 			defer func() {
 				if r := recover(); r != nil {
 					err = fmt.Errorf("dag: task:%s panic:%v", task.Name(), r)
 				}
-				done <- struct{}{}
+				done <- err
 			}()
-			runWithRetry()
+			err = runWithRetry()
 		}()
-		timer := time.NewTimer(op.timeout)
+		timer := n.ds.clock.NewTimer(op.timeout)
 		defer timer.Stop()
 		select {
-		case <-timer.C:
-			// timeout
-			err = fmt.Errorf("dag: task:%s run timeout", task.Name())
-		case <-done:
+		case <-timer.C():
+			lock.Lock()
+			timedOut = true
+			lock.Unlock()
+			return taskTimeoutError(task.Name())
+		case err := <-done:
+			return err
 		}
+	}
+	return runWithRetry()
+}
+
+// taskTimeoutError is returned when task run timeout, it wraps ErrTaskTimeout
+type taskTimeoutError string
+
+func (e taskTimeoutError) Error() string {
+	return "dag: task:" + string(e) + " run timeout"
+}
+
+func (e taskTimeoutError) Unwrap() error {
+	return ErrTaskTimeout
+}
+
+func (n *node[T]) ready() {
+	n.mu.Lock()
+	n.report.Ready = n.ds.clock.Now()
+	n.mu.Unlock()
+}
+
+func (n *node[T]) running() {
+	n.mu.Lock()
+	n.report.Status = TaskRunning
+	n.report.Start = n.ds.clock.Now()
+	n.mu.Unlock()
+}
+
+func (n *node[T]) attempt(i int) {
+	n.mu.Lock()
+	n.report.Attempts = i
+	n.mu.Unlock()
+}
+
+func (n *node[T]) finish(ctx context.Context, skipped bool, err error) {
+	n.mu.Lock()
+	switch {
+	case skipped:
+		n.report.Status = TaskSkipped
+	case err != nil:
+		n.report.Status = TaskFailed
+		n.report.Err = err
+	case n.restored != nil:
+		n.report.Status = TaskRestored
+	case n.cached:
+		n.report.Status = TaskCached
+	case n.fellBack:
+		n.report.Status = TaskFallback
+	default:
+		n.report.Status = TaskSuccess
+	}
+	if !n.report.Start.IsZero() && n.report.End.IsZero() {
+		n.report.End = n.ds.clock.Now()
+	}
+	attempts := n.report.Attempts
+	n.mu.Unlock()
+	if skipped {
+		n.ds.listeners.OnTaskSkip(ctx, n.event(0, ErrSkipped))
 	} else {
-		runWithRetry()
+		n.ds.listeners.OnTaskEnd(ctx, n.event(attempts, err))
 	}
-	return err
 }
 
-func (n *node[T]) breakNext() {
-	for _, n2 := range n.next {
-		n2.preBreak.Add(-1)
+func (n *node[T]) event(attempt int, err error) TaskEvent {
+	r := n.getReport()
+	return TaskEvent{RunID: n.ds.runID, Scheduler: n.ds.name, Task: r.Name, Group: r.Group, Attempt: attempt,
+		Ready: r.Ready, Start: r.Start, End: r.End, Err: err, Throttled: r.Throttled, Hedged: r.Hedged, HedgeWins: r.HedgeWins}
+}
+
+func (n *node[T]) getReport() TaskReport {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.report
+}
+
+// complete mark node done, and break next nodes if broke
+func (n *node[T]) complete(broke bool) {
+	n.ds.lock.Lock()
+	n.done, n.broke = true, broke
+	n.ds.completed++
+	n.order = n.ds.completed
+	next := n.next
+	n.ds.lock.Unlock()
+	if broke {
+		for _, n2 := range next {
+			n2.preBreak.Add(-1)
+		}
 	}
 }
 
 // NewScheduler build a typed task scheduler
 func NewScheduler[T any]() *Scheduler[T] {
-	return &Scheduler[T]{dag: NewGraph(), nodes: make(map[string]*node[T], 0)}
+	return &Scheduler[T]{dag: NewGraph(), nodes: make(map[string]*node[T], 0), clock: SystemClock}
+}
+
+// WithName set name of scheduler, which is used to identify it in debug handler and so on
+func (d *Scheduler[T]) WithName(name string) *Scheduler[T] {
+	d.name = name
+	return d
+}
+
+// Name return name of scheduler
+func (d *Scheduler[T]) Name() string {
+	return d.name
 }
 
 // WithInjectorFactory add a injectorFactory, which run before/after each task.
@@ -177,6 +373,62 @@ func (d *Scheduler[T]) WithInjectorFactory(injectFac InjectorFactory[T]) *Schedu
 	return d
 }
 
+// Use add middlewares around execution of each task, the first one is the outermost
+func (d *Scheduler[T]) Use(mws ...Middleware[T]) *Scheduler[T] {
+	d.middlewares = append(d.middlewares, mws...)
+	return d
+}
+
+// WithLogger set logger of scheduler, which logs lifecycle of run and tasks,
+// and tasks can get a task-scoped logger by LoggerFromContext. the last logger set is used,
+// and slog.Default() is used if it's nil
+func (d *Scheduler[T]) WithLogger(logger *slog.Logger) *Scheduler[T] {
+	d.logger = logger
+	if d.logging {
+		return d
+	}
+	d.logging = true
+	return d.WithListener(logListener{logger: &d.logger})
+}
+
+// RunID return id of the run, which is generated when Run start
+func (d *Scheduler[T]) RunID() string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.runID
+}
+
+// WithListener add listeners of lifecycle events
+func (d *Scheduler[T]) WithListener(listeners ...Listener) *Scheduler[T] {
+	d.listeners = append(d.listeners, listeners...)
+	return d
+}
+
+// WithGroupConcurrency limit the max number of tasks of group running at the same time
+func (d *Scheduler[T]) WithGroupConcurrency(group string, n int) *Scheduler[T] {
+	d.group(group).concurrency = n
+	return d
+}
+
+// WithGroupTimeout set the default timeout of each task in group,
+// which works for the tasks without their own Timeout option
+func (d *Scheduler[T]) WithGroupTimeout(group string, timeout time.Duration) *Scheduler[T] {
+	d.group(group).timeout = timeout
+	return d
+}
+
+func (d *Scheduler[T]) group(name string) *groupLimit {
+	if d.groups == nil {
+		d.groups = make(map[string]*groupLimit)
+	}
+	g, ok := d.groups[name]
+	if !ok {
+		g = &groupLimit{}
+		d.groups[name] = g
+	}
+	return g
+}
+
 // NewWithInjectorFactory is shortcut of NewScheduler.WithInjectorFactory
 func NewWithInjectorFactory[T any](injectFac InjectorFactory[T]) *Scheduler[T] {
 	s := NewScheduler[T]().WithInjectorFactory(injectFac)
@@ -197,13 +449,60 @@ func (d *Scheduler[T]) Submit(tasks ...Task[T]) error {
 			d.err = ErrTaskExist
 			return d.err
 		}
-		n := &node[T]{task: task, ds: d}
+		n, err := d.newNode(task)
+		if err != nil {
+			d.err = err
+			return d.err
+		}
 		d.dag.AddNode(n)
 		d.nodes[task.Name()] = n
 	}
 	return nil
 }
 
+func (d *Scheduler[T]) newNode(task Task[T]) (*node[T], error) {
+	n := &node[T]{task: task, ds: d, opt: taskOption(task)}
+	for _, mw := range n.opt.middlewares {
+		if _, ok := mw.(Middleware[T]); !ok {
+			return nil, fmt.Errorf("%w: task:%s middleware type:%T", ErrInvalidOption, task.Name(), mw)
+		}
+	}
+	if err := checkCompensate[T](task.Name(), n.opt); err != nil {
+		return nil, err
+	}
+	if err := checkFallback[T](task.Name(), n.opt); err != nil {
+		return nil, err
+	}
+	if b := n.opt.breaker; b != nil && (b.threshold < 1 || b.coolDown <= 0) {
+		return nil, fmt.Errorf("%w: task:%s circuit breaker threshold:%d cool down:%s",
+			ErrInvalidOption, task.Name(), b.threshold, b.coolDown)
+	} else if b != nil {
+		if _, err := circuitOf(task.Name(), b); err != nil {
+			return nil, err
+		}
+	}
+	if l := n.opt.limit; l != nil && (l.n < 1 || l.per <= 0) {
+		return nil, fmt.Errorf("%w: task:%s rate limit:%d per:%s", ErrInvalidOption, task.Name(), l.n, l.per)
+	} else if l != nil {
+		if _, err := limiterOf(task.Name(), l, d.clock.Now()); err != nil {
+			return nil, err
+		}
+	}
+	n.report = TaskReport{Name: task.Name(), Group: n.opt.group, Status: TaskPending}
+	return n, nil
+}
+
+// prepare resolve options of node by group limits and build its handler
+func (d *Scheduler[T]) prepare(n *node[T]) {
+	if n.opt.timeout > 0 {
+		n.deadlineSource = DeadlineTask
+	} else if g := d.groups[n.opt.group]; g != nil && g.timeout > 0 {
+		n.opt.timeout = g.timeout
+		n.deadlineSource = DeadlineGroup
+	}
+	n.handle = n.chain()
+}
+
 // SubmitFunc submit a func task to scheduler
 func (d *Scheduler[T]) SubmitFunc(name string, f func(context.Context, T) error, deps ...string) error {
 	return d.SubmitFuncWithOps(name, f, nil, deps...)
@@ -247,8 +546,47 @@ func (d *Scheduler[T]) Run(ctx context.Context, x T) error {
 	if d.err != nil {
 		return d.err
 	}
+	return d.runWithID(ctx, newRunID(), x)
+}
+
+func (d *Scheduler[T]) runWithID(ctx context.Context, runID string, x T) error {
 	d.sealed = true
+	d.lock.Lock()
+	d.start = d.clock.Now()
+	d.runID = runID
+	d.lock.Unlock()
+	d.listeners.OnRunStart(ctx, RunEvent{RunID: d.runID, Scheduler: d.name, Start: d.start})
+	err := d.run(ctx, x)
+	if err != nil {
+		for _, n := range d.nodes {
+			n.mu.Lock()
+			canceled := n.report.Status == TaskPending
+			if canceled {
+				n.report.Status = TaskCanceled
+			}
+			n.mu.Unlock()
+			if canceled {
+				d.listeners.OnTaskSkip(ctx, n.event(0, ErrCanceled))
+			}
+		}
+		err = d.compensate(ctx, x, err)
+	}
+	d.lock.Lock()
+	d.end = d.clock.Now()
+	d.runErr = err
+	d.lock.Unlock()
+	d.listeners.OnRunEnd(ctx, RunEvent{RunID: d.runID, Scheduler: d.name, Start: d.start, End: d.end, Err: err})
+	return err
+}
+
+func (d *Scheduler[T]) run(ctx context.Context, x T) error {
+	for _, g := range d.groups {
+		if g.concurrency > 0 {
+			g.sem = make(chan struct{}, g.concurrency)
+		}
+	}
 	for _, n := range d.nodes {
+		d.prepare(n)
 		for _, name := range n.task.Dependencies() {
 			pre, ok := d.nodes[name]
 			if !ok {
@@ -260,7 +598,9 @@ func (d *Scheduler[T]) Run(ctx context.Context, x T) error {
 	}
 	var visitedNodesNum int
 	// init nodes inDegrees
-	inDegrees := make(map[string]int, len(d.nodes))
+	d.lock.Lock()
+	d.inDegrees = make(map[string]int, len(d.nodes))
+	inDegrees := d.inDegrees
 	for _, n := range d.nodes {
 		inDegrees[n.Name()] = 0
 	}
@@ -280,6 +620,13 @@ func (d *Scheduler[T]) Run(ctx context.Context, x T) error {
 			curN.preBreak.Store(int64(inDegree))
 		}
 	}
+	d.running = true
+	d.lock.Unlock()
+	defer func() {
+		d.lock.Lock()
+		d.running = false
+		d.lock.Unlock()
+	}()
 	for len(toStartNodes) > 0 {
 		d.swg = new(sync.WaitGroup)
 		d.swg.Add(len(toStartNodes))
@@ -288,11 +635,17 @@ func (d *Scheduler[T]) Run(ctx context.Context, x T) error {
 			visitedNodesNum++
 		}
 		d.swg.Wait()
+		if d.err == nil && ctx.Err() != nil {
+			// canceled by ctx, eg: the parent of sub dag canceled
+			d.CancelWithErr(ctx.Err())
+		}
 		if d.err != nil {
+			d.runAlways(ctx, x)
 			return d.err
 		}
 		pre := toStartNodes
 		toStartNodes = []*node[T]{}
+		d.lock.Lock()
 		for _, startNode := range pre {
 			for _, n := range startNode.next {
 				inDegrees[n.Name()]--
@@ -301,8 +654,13 @@ func (d *Scheduler[T]) Run(ctx context.Context, x T) error {
 				}
 			}
 		}
+		toStartNodes = append(toStartNodes, d.spawned...)
+		d.spawned = nil
+		d.lock.Unlock()
 	}
 	// check circle
+	d.lock.Lock()
+	defer d.lock.Unlock()
 	if visitedNodesNum < len(d.dag.Nodes) {
 		var circleNodes []string
 		for n, inDegree := range inDegrees {
@@ -342,10 +700,10 @@ func (d *Scheduler[T]) WaitTimeout(duration time.Duration) error {
 	if duration <= 0 {
 		return d.Wait()
 	}
-	timer := time.NewTimer(duration)
+	timer := d.clock.NewTimer(duration)
 	defer timer.Stop()
 	select {
-	case <-timer.C:
+	case <-timer.C():
 		return ErrTimeout
 	case err := <-d.done:
 		return err
@@ -359,20 +717,38 @@ func (d *Scheduler[T]) CancelWithErr(err error) {
 	d.lock.Unlock()
 }
 
-// Dot dump dag in dot language
-func (d *Scheduler[T]) Dot(ops ...DotOption) string {
-	var branchNodesOps []DotOption
-	for _, n := range d.nodes {
-		if _, ok := n.task.(Conditioned[T]); ok {
-			branchNodesOps = append(branchNodesOps, WithNodeAttr(n.Name(), "shape=diamond", `color="blue"`))
+// Report return the report of tasks, it can be called while running or after run finished
+func (d *Scheduler[T]) Report() *RunReport {
+	d.lock.Lock()
+	r := &RunReport{RunID: d.runID, Start: d.start, End: d.end, Err: d.runErr}
+	d.lock.Unlock()
+	nodes := d.nodeList()
+	r.Tasks = make([]TaskReport, 0, len(nodes))
+	for _, n := range nodes {
+		tr := n.getReport()
+		if s, ok := n.task.(subScheduler); ok {
+			tr.Sub = s.subReport()
 		}
+		if l, ok := n.task.(iterator); ok {
+			tr.Iterations = l.iterationReports()
+		}
+		r.Tasks = append(r.Tasks, tr)
 	}
-	return d.dag.DOT(append(branchNodesOps, ops...)...)
+	sort.Slice(r.Tasks, func(i, j int) bool {
+		return r.Tasks[i].Name < r.Tasks[j].Name
+	})
+	return r
+}
+
+// Dot dump dag in dot language
+func (d *Scheduler[T]) Dot(ops ...DotOption) string {
+	v := d.view()
+	return v.graph().DOT(append(v.dotOptions(), ops...)...)
 }
 
 const graphvizOnlineURL = "https://dreampuf.github.io/GraphvizOnline/#"
 
-// DOTOnlineURL return a graphvizOnline url
+// DOTOnlineURL return a graphvizOnline url, note the dag is sent to the third-party site, use WriteHTML for a local page
 func (d *Scheduler[T]) DOTOnlineURL(ops ...DotOption) string {
 	dot := d.Dot(ops...)
 	escape := url.PathEscape(dot)
@@ -411,3 +411,115 @@ func TestAsyncRun(t *testing.T) {
 		checkEqual(t, n.name, value.(string))
 	}
 }
+
+func TestSchedulerGroup(t *testing.T) {
+	var nodes = []task{
+		{
+			name:    "F1",
+			options: []TaskOption{Group("fetch")},
+		},
+		{
+			name:    "F2",
+			options: []TaskOption{Group("fetch")},
+		},
+		{
+			name:    "F3",
+			options: []TaskOption{Group("fetch")},
+		},
+		{
+			name:         "P1",
+			dependencies: []string{"F1", "F2", "F3"},
+			options:      []TaskOption{Group("persist")},
+		},
+	}
+	start := time.Now().UnixMilli()
+	ds := NewScheduler[*sync.Map]().WithGroupConcurrency("fetch", 1)
+	for _, mt := range nodes {
+		checkNil(t, ds.Submit(mt))
+	}
+	runCtx := &sync.Map{}
+	checkNil(t, ds.Run(context.Background(), runCtx))
+	checkGreater(t, time.Now().UnixMilli()-start, int64(400))
+	groups := ds.Report().Groups()
+	checkEqual(t, 2, len(groups))
+	checkEqual(t, "fetch", groups[0].Group)
+	checkEqual(t, 3, groups[0].Statuses[TaskSuccess])
+	checkGreater(t, groups[0].Duration().Milliseconds(), int64(300))
+	var maxWait time.Duration
+	for _, tr := range groups[0].Tasks {
+		if tr.Wait() > maxWait {
+			maxWait = tr.Wait()
+		}
+	}
+	checkGreater(t, maxWait.Milliseconds(), int64(150))
+	checkEqual(t, "persist", groups[1].Group)
+	checkEqual(t, 1, len(groups[1].Tasks))
+	checkEqual(t, `digraph G {
+
+"start" [color="green",shape=doublecircle]
+"end" [color="red",shape=doublecircle]
+
+subgraph "cluster_fetch" {
+label="fetch"
+"F1";"F2";"F3"
+}
+
+subgraph "cluster_persist" {
+label="persist"
+"P1"
+}
+
+"F1" -> {"P1"}
+"F2" -> {"P1"}
+"F3" -> {"P1"}
+"P1" -> {"end"}
+"start" -> {"F1","F2","F3"}
+}`, ds.Dot())
+}
+
+func TestSchedulerGroupTimeout(t *testing.T) {
+	ds := NewScheduler[*sync.Map]().WithGroupTimeout("fetch", 50*time.Millisecond)
+	checkNil(t, ds.Submit(task{name: "T1", options: []TaskOption{Group("fetch")}}))
+	checkNil(t, ds.Submit(task{name: "T2", options: []TaskOption{Group("fetch"), Timeout(time.Second)}}))
+	err := ds.Run(context.Background(), &sync.Map{})
+	checkEqual(t, "dag: task:T1 run timeout", err.Error())
+	report := ds.Report()
+	t1, _ := report.Task("T1")
+	checkEqual(t, TaskFailed, t1.Status)
+	t2, _ := report.Task("T2")
+	checkEqual(t, TaskSuccess, t2.Status)
+}
+
+func TestSubmitBranchFunc(t *testing.T) {
+	var ran sync.Map
+	ds := NewScheduler[any]()
+	var submit = func(name string, valid bool, ops ...TaskOption) {
+		checkNil(t, ds.SubmitBranchFuncWithOps(name, func(ctx context.Context, _ any) (bool, error) {
+			return valid, nil
+		}, ops))
+		checkNil(t, ds.SubmitFunc(name+"-next", func(ctx context.Context, _ any) error {
+			ran.Store(name, true)
+			return nil
+		}, name))
+	}
+	submit("B1", true, Group("g"))
+	submit("B2", false)
+	checkNil(t, ds.Run(context.Background(), nil))
+	_, ok := ran.Load("B1")
+	checkEqual(t, true, ok)
+	_, ok = ran.Load("B2")
+	checkEqual(t, false, ok)
+	report := ds.Report()
+	tr, _ := report.Task("B2-next")
+	checkEqual(t, TaskSkipped, tr.Status)
+	// options of branch func are applied
+	tr, _ = report.Task("B1")
+	checkEqual(t, "g", tr.Group)
+
+	ds = NewScheduler[any]()
+	checkNil(t, ds.SubmitBranchFuncWithOps("B3", func(ctx context.Context, _ any) (bool, error) {
+		<-ctx.Done()
+		return true, nil
+	}, []TaskOption{Timeout(10 * time.Millisecond)}))
+	checkEqual(t, true, errors.Is(ds.Run(context.Background(), nil), ErrTaskTimeout))
+}
@@ -0,0 +1,181 @@
+package dagRun
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+type spawnKey struct{}
+
+// Spawn add tasks to the running scheduler from ctx passed to Task.Execute, eg: a crawler adds pages
+// it found. dependencies of tasks must be known tasks or tasks spawned together, a task starts
+// when its dependencies finished, and Run returns after spawned tasks finished too.
+// with a state store, checkpoint of the spawning task waits until spawned tasks succeeded, so they
+// are spawned again when resumed, and spawned tasks succeeded before are restored then
+func Spawn[T any](ctx context.Context, tasks ...Task[T]) error {
+	n, ok := spawnerFrom[T](ctx)
+	if !ok {
+		return ErrNotRunning
+	}
+	return n.ds.spawn(n, tasks, "")
+}
+
+// spawnerFrom return the node executing with ctx
+func spawnerFrom[T any](ctx context.Context) (*node[T], bool) {
+	n, ok := ctx.Value(spawnKey{}).(*node[T])
+	return n, ok
+}
+
+// spawn add tasks spawned by spawner to the running scheduler, the known task before waits for them too
+// if set, it must not have started yet, eg: reduce node of map waits for children spawned by map node
+func (d *Scheduler[T]) spawn(spawner *node[T], tasks []Task[T], before string) error {
+	var nodes = make(map[string]*node[T], len(tasks))
+	for _, task := range tasks {
+		if task == nil {
+			return ErrNilTask
+		}
+		n, err := d.newNode(task)
+		if err != nil {
+			return err
+		}
+		if _, has := nodes[task.Name()]; has {
+			return fmt.Errorf("%w: task:%s", ErrTaskExist, task.Name())
+		}
+		nodes[task.Name()] = n
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if !d.running {
+		return ErrNotRunning
+	}
+	if d.err != nil {
+		return d.err
+	}
+	order, err := d.spawnOrder(nodes)
+	if err != nil {
+		return err
+	}
+	var last *node[T]
+	if before != "" {
+		var ok bool
+		if last, ok = d.nodes[before]; !ok {
+			return fmt.Errorf("dag:%w: task:%s", ErrTaskNotExist, before)
+		}
+		if last.done || d.inDegrees[before] == 0 {
+			return fmt.Errorf("dag: task:%s has started before tasks spawned", before)
+		}
+	}
+	for _, n := range order {
+		d.prepare(n)
+		var preBreak, inDegree int
+		for _, name := range n.task.Dependencies() {
+			pre := d.nodes[name]
+			d.dag.AddEdge(pre, n)
+			if !pre.done {
+				// pre breaks or starts n when finished
+				pre.next = append(pre.next, n)
+				preBreak++
+				inDegree++
+			} else if !pre.broke {
+				preBreak++
+			}
+		}
+		if len(n.task.Dependencies()) == 0 {
+			// dummy head like start nodes
+			preBreak = 1
+		}
+		n.preBreak.Store(int64(preBreak))
+		d.dag.AddNode(n)
+		d.nodes[n.Name()] = n
+		d.inDegrees[n.Name()] = inDegree
+		if inDegree == 0 {
+			d.spawned = append(d.spawned, n)
+		}
+		if last != nil {
+			d.dag.AddEdge(n, last)
+			n.next = append(n.next, last)
+			last.preBreak.Add(1)
+			d.inDegrees[before]++
+		}
+		n.restored = d.states[n.Name()]
+		n.owners = append(n.owners, spawner)
+		spawner.waiting++
+	}
+	if last != nil {
+		last.owners = append(last.owners, spawner)
+		spawner.waiting++
+	}
+	return nil
+}
+
+// spawnOrder check names and dependencies of spawned nodes incrementally, as known nodes can't
+// depend on spawned ones, a circle can only be among spawned nodes. nodes are returned in topological order,
+// it must be called with lock held
+func (d *Scheduler[T]) spawnOrder(nodes map[string]*node[T]) ([]*node[T], error) {
+	inDegrees := make(map[string]int, len(nodes))
+	next := make(map[string][]string, len(nodes))
+	for name, n := range nodes {
+		if _, has := d.nodes[name]; has {
+			return nil, fmt.Errorf("%w: task:%s", ErrTaskExist, name)
+		}
+		inDegrees[name] = 0
+		for _, dep := range n.task.Dependencies() {
+			if _, ok := nodes[dep]; ok {
+				inDegrees[name]++
+				next[dep] = append(next[dep], name)
+			} else if _, ok := d.nodes[dep]; !ok {
+				return nil, fmt.Errorf("dag:%w: task :%s's dependency:%s not found", ErrTaskNotExist, name, dep)
+			}
+		}
+	}
+	var queue []string
+	for name, inDegree := range inDegrees {
+		if inDegree == 0 {
+			queue = append(queue, name)
+		}
+	}
+	sort.Strings(queue)
+	var order []*node[T]
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		order = append(order, nodes[name])
+		for _, to := range next[name] {
+			inDegrees[to]--
+			if inDegrees[to] == 0 {
+				queue = append(queue, to)
+			}
+		}
+	}
+	if len(order) < len(nodes) {
+		var circleNodes []string
+		for name, inDegree := range inDegrees {
+			if inDegree != 0 {
+				circleNodes = append(circleNodes, name)
+			}
+		}
+		sort.Strings(circleNodes)
+		return nil, fmt.Errorf("dag:graph has circle in nodes:%v", circleNodes)
+	}
+	return order, nil
+}
+
+// node return node by name, it's safe while running
+func (d *Scheduler[T]) node(name string) (*node[T], bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	n, ok := d.nodes[name]
+	return n, ok
+}
+
+// nodeList return all nodes, it's safe while running
+func (d *Scheduler[T]) nodeList() []*node[T] {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	nodes := make([]*node[T], 0, len(d.nodes))
+	for _, n := range d.nodes {
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
@@ -0,0 +1,141 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type crawlTask struct {
+	page  int
+	deps  []string
+	depth int
+}
+
+func (c crawlTask) Name() string           { return fmt.Sprintf("page%d", c.page) }
+func (c crawlTask) Dependencies() []string { return c.deps }
+
+func (c crawlTask) Execute(ctx context.Context, x *counterCtx) error {
+	x.set(c.Name(), c.page)
+	if c.depth == 0 {
+		return nil
+	}
+	// each page links two new pages
+	return Spawn[*counterCtx](ctx,
+		crawlTask{page: c.page*2 + 1, depth: c.depth - 1, deps: []string{c.Name()}},
+		crawlTask{page: c.page*2 + 2, depth: c.depth - 1, deps: []string{c.Name()}},
+	)
+}
+
+func TestSpawn(t *testing.T) {
+	ds := NewScheduler[*counterCtx]()
+	checkNil(t, ds.Submit(crawlTask{page: 0, depth: 3}))
+	var merged bool
+	checkNil(t, ds.SubmitFunc("index", func(ctx context.Context, x *counterCtx) error {
+		// depends on a finished task and a task not started
+		return Spawn[*counterCtx](ctx, &funcTaskImpl[*counterCtx]{name: "merge", deps: []string{"page0", "index2"},
+			f: func(ctx context.Context, x *counterCtx) error {
+				merged = true
+				return nil
+			}})
+	}, "page0"))
+	checkNil(t, ds.SubmitFunc("index2", func(ctx context.Context, x *counterCtx) error { return nil }, "index"))
+	x := newCounterCtx()
+	checkNil(t, ds.Run(context.Background(), x))
+	checkEqual(t, 15, len(x.runs))
+	checkEqual(t, true, merged)
+	report := ds.Report()
+	checkEqual(t, 18, len(report.Tasks))
+	for _, tr := range report.Tasks {
+		checkEqual(t, TaskSuccess, tr.Status)
+	}
+}
+
+func TestSpawnBranch(t *testing.T) {
+	ds := NewScheduler[any]()
+	var ran bool
+	checkNil(t, ds.SubmitBranchFunc("B", func(ctx context.Context, _ any) (bool, error) { return false, nil }))
+	checkNil(t, ds.SubmitFunc("T1", func(ctx context.Context, _ any) error {
+		return Spawn[any](ctx, &funcTaskImpl[any]{name: "T2", deps: []string{"B"},
+			f: func(ctx context.Context, _ any) error {
+				ran = true
+				return nil
+			}})
+	}, "B"))
+	checkNil(t, ds.SubmitFunc("T0", func(ctx context.Context, _ any) error {
+		return Spawn[any](ctx, &funcTaskImpl[any]{name: "T3", deps: []string{"B"},
+			f: func(ctx context.Context, _ any) error {
+				ran = true
+				return nil
+			}})
+	}))
+	checkNil(t, ds.Run(context.Background(), nil))
+	checkEqual(t, false, ran)
+	tr, _ := ds.Report().Task("T3")
+	checkEqual(t, TaskSkipped, tr.Status)
+}
+
+func TestSpawnErr(t *testing.T) {
+	checkEqual(t, ErrNotRunning, Spawn[any](context.Background(), &funcTaskImpl[any]{name: "T"}))
+	var nop = func(ctx context.Context, _ any) error { return nil }
+	var errs = map[string]error{}
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitFunc("T1", func(ctx context.Context, _ any) error {
+		errs["circle"] = Spawn[any](ctx,
+			&funcTaskImpl[any]{name: "A", deps: []string{"B", "T1"}, f: nop},
+			&funcTaskImpl[any]{name: "B", deps: []string{"A"}, f: nop})
+		errs["exist"] = Spawn[any](ctx, &funcTaskImpl[any]{name: "T1", f: nop})
+		errs["not found"] = Spawn[any](ctx, &funcTaskImpl[any]{name: "C", deps: []string{"X"}, f: nop})
+		return nil
+	}))
+	checkNil(t, ds.Run(context.Background(), nil))
+	checkNotNil(t, errs["circle"])
+	checkEqual(t, true, errors.Is(errs["exist"], ErrTaskExist))
+	checkEqual(t, true, errors.Is(errs["not found"], ErrTaskNotExist))
+	checkEqual(t, 1, len(ds.Report().Tasks))
+}
+
+func TestSpawnResume(t *testing.T) {
+	store := NewMemoryStateStore()
+	var runs sync.Map
+	var count = func(name string) int {
+		v, _ := runs.Load(name)
+		n, _ := v.(int)
+		return n
+	}
+	var build = func(failPage error) *Scheduler[any] {
+		ds := NewScheduler[any]().WithStateStore(store)
+		var page = func(name string, err error) Task[any] {
+			return &funcTaskImpl[any]{name: name, f: func(ctx context.Context, _ any) error {
+				runs.Store(name, count(name)+1)
+				return err
+			}}
+		}
+		checkNil(t, ds.SubmitFunc("index", func(ctx context.Context, _ any) error {
+			runs.Store("index", count("index")+1)
+			return Spawn[any](ctx, page("page0", nil), page("page1", failPage))
+		}))
+		return ds
+	}
+	first := build(errors.New("expect err in page1"))
+	checkNotNil(t, first.Run(context.Background(), nil))
+
+	// index spawns again, page0 succeeded is restored and page1 runs again
+	second := build(nil)
+	checkNil(t, second.Resume(context.Background(), first.RunID(), nil))
+	checkEqual(t, 2, count("index"))
+	checkEqual(t, 1, count("page0"))
+	checkEqual(t, 2, count("page1"))
+	report := second.Report()
+	for name, status := range map[string]TaskStatus{"index": TaskSuccess, "page0": TaskRestored, "page1": TaskSuccess} {
+		tr, _ := report.Task(name)
+		checkEqual(t, status, tr.Status)
+	}
+
+	// all succeeded, nothing to run
+	checkNil(t, build(nil).Resume(context.Background(), first.RunID(), nil))
+	checkEqual(t, 2, count("index"))
+	checkEqual(t, 2, count("page1"))
+}
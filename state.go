@@ -0,0 +1,240 @@
+package dagRun
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outputter is implemented by tasks which can serialize their output in runCtx,
+// so the output can be restored when run is resumed
+type Outputter[T any] interface {
+	MarshalOutput(ctx context.Context, t T) ([]byte, error)
+	UnmarshalOutput(ctx context.Context, t T, data []byte) error
+}
+
+// TaskState is the checkpoint of a succeeded task
+type TaskState struct {
+	Task string `json:"task"`
+	// Valid is the result of ValidBranch for branch task, true for others
+	Valid  bool      `json:"valid"`
+	Output []byte    `json:"output,omitempty"`
+	End    time.Time `json:"end"`
+	// Compensated invalidates the former checkpoint of task, which is compensated as run failed
+	Compensated bool `json:"compensated,omitempty"`
+}
+
+// StateStore saves checkpoints of tasks by run id, it should be safe for concurrent use
+type StateStore interface {
+	Save(ctx context.Context, runID string, state TaskState) error
+	// Load return states of run, the latest state wins if a task has more than one
+	Load(ctx context.Context, runID string) ([]TaskState, error)
+}
+
+// WithStateStore set store to save checkpoint of each succeeded task, so a run can be resumed by Resume
+func (d *Scheduler[T]) WithStateStore(store StateStore) *Scheduler[T] {
+	d.store = store
+	return d
+}
+
+// Resume run tasks with the id of an interrupted run, tasks succeeded in that run are not executed again,
+// their output are restored if they implement Outputter. tasks compensated in that run are executed again
+func (d *Scheduler[T]) Resume(ctx context.Context, runID string, x T) error {
+	if d.err != nil {
+		return d.err
+	}
+	if d.store == nil {
+		return ErrNoStateStore
+	}
+	states, err := d.store.Load(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("dag: load states of run:%s err:%w", runID, err)
+	}
+	d.states = make(map[string]*TaskState, len(states))
+	for i := range states {
+		d.states[states[i].Task] = &states[i]
+		if states[i].Compensated {
+			delete(d.states, states[i].Task)
+		}
+	}
+	for name, state := range d.states {
+		if n, ok := d.nodes[name]; ok {
+			n.restored = state
+		}
+	}
+	return d.runWithID(ctx, runID, x)
+}
+
+func (n *node[T]) restore(ctx context.Context, t T) error {
+	if o, ok := n.task.(Outputter[T]); ok && len(n.restored.Output) > 0 {
+		if err := o.UnmarshalOutput(ctx, t, n.restored.Output); err != nil {
+			return fmt.Errorf("dag: restore output of task:%s err:%w", n.Name(), err)
+		}
+	}
+	return nil
+}
+
+// checkpoint save state of node finished without error, or wait until tasks it spawned are saved,
+// so they are spawned again when resumed. skipped and restored nodes are not saved again
+func (n *node[T]) checkpoint(ctx context.Context, t T, valid, skipped bool) error {
+	n.ds.lock.Lock()
+	n.valid = valid
+	n.unsaved = n.waiting > 0
+	n.ds.lock.Unlock()
+	if n.unsaved {
+		return nil
+	}
+	return n.settle(ctx, t, skipped)
+}
+
+// settle save state of node, and then owners waiting for no other tasks
+func (n *node[T]) settle(ctx context.Context, t T, skipped bool) error {
+	if !skipped && n.restored == nil {
+		if err := n.saveState(ctx, t, n.valid); err != nil {
+			return err
+		}
+	}
+	var settled []*node[T]
+	n.ds.lock.Lock()
+	for _, o := range n.owners {
+		o.waiting--
+		if o.waiting == 0 && o.unsaved {
+			o.unsaved = false
+			settled = append(settled, o)
+		}
+	}
+	n.ds.lock.Unlock()
+	for _, o := range settled {
+		if err := o.settle(ctx, t, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *node[T]) saveState(ctx context.Context, t T, valid bool) error {
+	// task ends before its checkpoint saved, finish keeps the end time
+	n.mu.Lock()
+	if n.report.End.IsZero() {
+		n.report.End = n.ds.clock.Now()
+	}
+	state := TaskState{Task: n.Name(), Valid: valid, End: n.report.End}
+	n.mu.Unlock()
+	if o, ok := n.task.(Outputter[T]); ok {
+		output, err := o.MarshalOutput(ctx, t)
+		if err != nil {
+			return fmt.Errorf("dag: marshal output of task:%s err:%w", n.Name(), err)
+		}
+		state.Output = output
+	}
+	if err := n.ds.store.Save(ctx, n.ds.runID, state); err != nil {
+		return fmt.Errorf("dag: save state of task:%s err:%w", n.Name(), err)
+	}
+	return nil
+}
+
+// MemoryStateStore is a StateStore in memory
+type MemoryStateStore struct {
+	lock sync.Mutex
+	runs map[string][]TaskState
+}
+
+// NewMemoryStateStore build a StateStore in memory
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{runs: map[string][]TaskState{}}
+}
+
+// Save implements StateStore
+func (m *MemoryStateStore) Save(_ context.Context, runID string, state TaskState) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.runs[runID] = append(m.runs[runID], state)
+	return nil
+}
+
+// Load implements StateStore
+func (m *MemoryStateStore) Load(_ context.Context, runID string) ([]TaskState, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]TaskState(nil), m.runs[runID]...), nil
+}
+
+// FileStateStore is a StateStore saving states of each run in a json lines file of dir,
+// states are appended and synced to file one by one, so they survive process crash
+type FileStateStore struct {
+	dir  string
+	lock sync.Mutex
+}
+
+// NewFileStateStore build a FileStateStore, dir is created if not exist
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStateStore{dir: dir}, nil
+}
+
+func (f *FileStateStore) filename(runID string) (string, error) {
+	if runID == "" || strings.ContainsAny(runID, `/\`) || runID == "." || runID == ".." {
+		return "", fmt.Errorf("dag: invalid run id:%q", runID)
+	}
+	return filepath.Join(f.dir, runID+".jsonl"), nil
+}
+
+// Save implements StateStore
+func (f *FileStateStore) Save(_ context.Context, runID string, state TaskState) error {
+	filename, err := f.filename(runID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(data, '\n')); err == nil {
+		err = file.Sync()
+	}
+	return errors.Join(err, file.Close())
+}
+
+// Load implements StateStore, a broken line written when crash is ignored
+func (f *FileStateStore) Load(_ context.Context, runID string) ([]TaskState, error) {
+	filename, err := f.filename(runID)
+	if err != nil {
+		return nil, err
+	}
+	f.lock.Lock()
+	data, err := os.ReadFile(filename)
+	f.lock.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var states []TaskState
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		var state TaskState
+		if err := json.Unmarshal(scanner.Bytes(), &state); err != nil {
+			continue
+		}
+		states = append(states, state)
+	}
+	return states, scanner.Err()
+}
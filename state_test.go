@@ -0,0 +1,156 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type counterCtx struct {
+	lock    sync.Mutex
+	outputs map[string]int
+	runs    map[string]int
+}
+
+func (c *counterCtx) set(name string, v int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.outputs[name] = v
+	c.runs[name]++
+}
+
+type outputTask struct {
+	name string
+	deps []string
+	err  error
+}
+
+func (o outputTask) Name() string           { return o.name }
+func (o outputTask) Dependencies() []string { return o.deps }
+
+func (o outputTask) Execute(_ context.Context, c *counterCtx) error {
+	if o.err != nil {
+		return o.err
+	}
+	sum := 1
+	c.lock.Lock()
+	for _, dep := range o.deps {
+		sum += c.outputs[dep]
+	}
+	c.lock.Unlock()
+	c.set(o.name, sum)
+	return nil
+}
+
+func (o outputTask) MarshalOutput(_ context.Context, c *counterCtx) ([]byte, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return []byte(strconv.Itoa(c.outputs[o.name])), nil
+}
+
+func (o outputTask) UnmarshalOutput(_ context.Context, c *counterCtx, data []byte) error {
+	v, err := strconv.Atoi(string(data))
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.outputs[o.name] = v
+	return nil
+}
+
+func newCounterCtx() *counterCtx {
+	return &counterCtx{outputs: map[string]int{}, runs: map[string]int{}}
+}
+
+func testResume(t *testing.T, store StateStore) {
+	var build = func(failT3 error) *Scheduler[*counterCtx] {
+		ds := NewScheduler[*counterCtx]().WithStateStore(store)
+		checkNil(t, ds.Submit(
+			outputTask{name: "T1"},
+			outputTask{name: "T2", deps: []string{"T1"}},
+			outputTask{name: "T3", deps: []string{"T2"}, err: failT3},
+			outputTask{name: "T4", deps: []string{"B1"}},
+		))
+		checkNil(t, ds.SubmitBranchFunc("B1", func(ctx context.Context, c *counterCtx) (bool, error) {
+			c.set("B1", 0)
+			return false, nil
+		}))
+		return ds
+	}
+	first := build(errors.New("expect err in T3"))
+	checkNotNil(t, first.Run(context.Background(), newCounterCtx()))
+	runID := first.RunID()
+	states, err := store.Load(context.Background(), runID)
+	checkNil(t, err)
+	checkEqual(t, 3, len(states))
+	for _, state := range states {
+		checkEqual(t, false, state.End.IsZero())
+		tr, _ := first.Report().Task(state.Task)
+		checkEqual(t, true, tr.End.Equal(state.End))
+	}
+
+	runCtx := newCounterCtx()
+	second := build(nil)
+	checkNil(t, second.Resume(context.Background(), runID, runCtx))
+	checkEqual(t, runID, second.RunID())
+	checkEqual(t, 0, runCtx.runs["T1"])
+	checkEqual(t, 0, runCtx.runs["T2"])
+	checkEqual(t, 0, runCtx.runs["B1"])
+	checkEqual(t, 0, runCtx.runs["T4"])
+	checkEqual(t, 1, runCtx.runs["T3"])
+	checkEqual(t, 3, runCtx.outputs["T3"])
+	report := second.Report()
+	wants := map[string]TaskStatus{"T1": TaskRestored, "T2": TaskRestored, "B1": TaskRestored,
+		"T3": TaskSuccess, "T4": TaskSkipped}
+	for name, status := range wants {
+		tr, _ := report.Task(name)
+		if tr.Status != status {
+			t.Errorf("task:%s want status:%s but get:%s", name, status, tr.Status)
+		}
+	}
+
+	// all tasks succeeded, nothing to run
+	runCtx = newCounterCtx()
+	checkNil(t, build(nil).Resume(context.Background(), runID, runCtx))
+	checkEqual(t, 0, len(runCtx.runs))
+	checkEqual(t, 3, runCtx.outputs["T3"])
+}
+
+func TestResumeMemoryStore(t *testing.T) {
+	testResume(t, NewMemoryStateStore())
+}
+
+func TestResumeFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStateStore(dir)
+	checkNil(t, err)
+	testResume(t, store)
+
+	// broken line written when crash is ignored
+	checkNil(t, store.Save(context.Background(), "run", TaskState{Task: "T1", Valid: true}))
+	f, err := os.OpenFile(filepath.Join(dir, "run.jsonl"), os.O_APPEND|os.O_WRONLY, 0)
+	checkNil(t, err)
+	_, err = f.WriteString(`{"task":"T2","va`)
+	checkNil(t, err)
+	checkNil(t, f.Close())
+	states, err := store.Load(context.Background(), "run")
+	checkNil(t, err)
+	checkEqual(t, 1, len(states))
+	checkEqual(t, "T1", states[0].Task)
+
+	states, err = store.Load(context.Background(), "not-exist")
+	checkNil(t, err)
+	checkEqual(t, 0, len(states))
+	_, err = store.Load(context.Background(), "../run")
+	checkNotNil(t, err)
+}
+
+func TestResumeWithoutStore(t *testing.T) {
+	ds := NewScheduler[any]()
+	checkEqual(t, ErrNoStateStore, ds.Resume(context.Background(), "run", nil))
+}
@@ -0,0 +1,89 @@
+package dagRun
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// subViewer is implemented by tasks running a whole scheduler, which is drawn as a cluster
+type subViewer interface {
+	subView() graphView
+}
+
+// subScheduler is implemented by tasks running a whole scheduler once
+type subScheduler interface {
+	subViewer
+	subReport() *RunReport
+}
+
+// SubmitSubDAG submit a whole scheduler as a task, it runs with runCtx and ctx of the task,
+// so it's canceled with the task. its report is nested in TaskReport.Sub, and it's drawn
+// as a cluster in Dot. a scheduler can only run once, so the task is not retried
+func (d *Scheduler[T]) SubmitSubDAG(name string, sub *Scheduler[T], deps ...string) error {
+	return d.SubmitSubDAGWithOps(name, sub, nil, deps...)
+}
+
+// SubmitSubDAGWithOps submit a whole scheduler as a task with options.
+// a scheduler can only run once, so Retry more than once and Hedge are rejected with ErrInvalidOption
+func (d *Scheduler[T]) SubmitSubDAGWithOps(name string, sub *Scheduler[T], ops []TaskOption, deps ...string) error {
+	if name == "" {
+		d.err = ErrNoTaskName
+		return d.err
+	}
+	if sub == nil {
+		d.err = ErrNilTask
+		return d.err
+	}
+	var o option
+	for _, op := range ops {
+		op(&o)
+	}
+	if o.retry > 1 || o.hedge > 0 {
+		d.err = fmt.Errorf("%w: sub dag:%s can only run once, retry:%d hedge:%s", ErrInvalidOption, name, o.retry, o.hedge)
+		return d.err
+	}
+	if sub.name == "" {
+		sub.name = name
+	}
+	d.err = d.Submit(&subDAG[T]{name: name, deps: deps, sub: sub, options: ops})
+	return d.err
+}
+
+type subDAG[T any] struct {
+	name    string
+	deps    []string
+	sub     *Scheduler[T]
+	options []TaskOption
+	ran     atomic.Bool
+}
+
+func (s *subDAG[T]) Name() string {
+	return s.name
+}
+
+func (s *subDAG[T]) Dependencies() []string {
+	return s.deps
+}
+
+func (s *subDAG[T]) Options() []TaskOption {
+	return s.options
+}
+
+func (s *subDAG[T]) Execute(ctx context.Context, t T) error {
+	if !s.ran.CompareAndSwap(false, true) {
+		return fmt.Errorf("%w: sub dag:%s can only run once", ErrSealed, s.name)
+	}
+	return s.sub.Run(ctx, t)
+}
+
+func (s *subDAG[T]) subReport() *RunReport {
+	if !s.ran.Load() {
+		return nil
+	}
+	return s.sub.Report()
+}
+
+func (s *subDAG[T]) subView() graphView {
+	return s.sub.view()
+}
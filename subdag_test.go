@@ -0,0 +1,77 @@
+package dagRun
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubDAG(t *testing.T) {
+	sub := NewScheduler[*counterCtx]()
+	checkNil(t, sub.Submit(
+		outputTask{name: "S1", deps: nil},
+		outputTask{name: "S2", deps: []string{"S1"}},
+	))
+	checkNil(t, sub.SubmitBranchFunc("SB", func(ctx context.Context, _ *counterCtx) (bool, error) { return true, nil }))
+	ds := NewScheduler[*counterCtx]()
+	checkNil(t, ds.Submit(outputTask{name: "T1"}))
+	checkNil(t, ds.SubmitSubDAG("sub", sub, "T1"))
+	checkNil(t, ds.Submit(outputTask{name: "T2", deps: []string{"sub"}}))
+
+	dot := ds.Dot()
+	for _, want := range []string{
+		`"sub" [shape=box3d]`,
+		`subgraph "cluster_sub" {` + "\n" + `label="sub"` + "\n" + `"sub/S1";"sub/S2";"sub/SB"`,
+		`"sub" -> {"T2","sub/S1","sub/SB"}`,
+		`"sub/S1" -> {"sub/S2"}`,
+		`"sub/SB" [color="blue",shape=diamond]`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("want %q in dot:\n%s", want, dot)
+		}
+	}
+	tr, _ := ds.Report().Task("sub")
+	checkEqual(t, true, tr.Sub == nil)
+
+	x := newCounterCtx()
+	checkNil(t, ds.Run(context.Background(), x))
+	checkEqual(t, 1, x.runs["S2"])
+	checkEqual(t, 1, x.runs["T2"])
+	tr, _ = ds.Report().Task("sub")
+	checkEqual(t, TaskSuccess, tr.Status)
+	checkEqual(t, false, tr.Sub == nil)
+	checkEqual(t, 3, len(tr.Sub.Tasks))
+	s2, _ := tr.Sub.Task("S2")
+	checkEqual(t, TaskSuccess, s2.Status)
+	checkEqual(t, "sub", sub.Name())
+}
+
+func TestSubDAGCancel(t *testing.T) {
+	sub := NewScheduler[any]()
+	checkNil(t, sub.SubmitFunc("S1", func(ctx context.Context, _ any) error {
+		<-ctx.Done()
+		return nil
+	}))
+	var ran bool
+	checkNil(t, sub.SubmitFunc("S2", func(ctx context.Context, _ any) error {
+		ran = true
+		return nil
+	}, "S1"))
+	ds := NewScheduler[any]()
+	checkNil(t, ds.SubmitSubDAGWithOps("sub", sub, []TaskOption{Timeout(20 * time.Millisecond)}))
+	err := ds.Run(context.Background(), nil)
+	checkEqual(t, true, errors.Is(err, ErrTaskTimeout))
+	// wait sub dag finished
+	for sub.Report().End.IsZero() {
+		time.Sleep(time.Millisecond)
+	}
+	checkEqual(t, false, ran)
+	checkNotNil(t, sub.Report().Err)
+	checkEqual(t, ErrNilTask, NewScheduler[any]().SubmitSubDAG("sub", nil))
+	for _, op := range []TaskOption{Retry(2), Hedge(time.Millisecond)} {
+		err = NewScheduler[any]().SubmitSubDAGWithOps("sub", NewScheduler[any](), []TaskOption{op})
+		checkEqual(t, true, errors.Is(err, ErrInvalidOption))
+	}
+}
@@ -2,6 +2,7 @@ package dagRun
 
 import (
 	"fmt"
+	"log/slog"
 )
 
 type DepTask interface {
@@ -17,6 +18,12 @@ func NewTaskManager[T DepTask]() TaskManager[T] {
 	return TaskManager[T]{Registry: NewRegistry[T]()}
 }
 
+// WithLogger return task manager logs with logger, slog.Default() is used if not set
+func (t TaskManager[T]) WithLogger(logger *slog.Logger) TaskManager[T] {
+	t.Registry = t.Registry.WithLogger(logger)
+	return t
+}
+
 // GetAllTaskWithDepsByName get all Tasks with their parent dependencies by names
 func (t TaskManager[T]) GetAllTaskWithDepsByName(taskNames []string) (map[string]T, error) {
 	allTasks := make(map[string]T)
@@ -27,6 +34,7 @@ func (t TaskManager[T]) GetAllTaskWithDepsByName(taskNames []string) (map[string
 		}
 		task, err := t.Get(taskName)
 		if err != nil {
+			t.log().Error("dagRun: task not registered", "task", taskName)
 			return fmt.Errorf("not get task by name:%s", taskName)
 		}
 		allTasks[task.Name()] = task
@@ -44,5 +52,6 @@ func (t TaskManager[T]) GetAllTaskWithDepsByName(taskNames []string) (map[string
 			return nil, err
 		}
 	}
+	t.log().Debug("dagRun: get tasks with dependencies", "names", taskNames, "count", len(allTasks))
 	return allTasks, nil
 }